@@ -0,0 +1,70 @@
+package main
+
+import (
+    "fmt"
+    "log/slog"
+    "os"
+    "strconv"
+
+    "todo-golang/internal/config"
+
+    "github.com/urfave/cli/v2"
+)
+
+var configCommand = &cli.Command{
+    Name:  "config",
+    Usage: "inspect the configuration",
+    Subcommands: []*cli.Command{
+        {
+            Name:  "print",
+            Usage: "print the effective configuration as YAML, with passwords redacted",
+            Action: func(cCtx *cli.Context) error {
+                cfg, err := loadConfig(cCtx)
+                if err != nil {
+                    return err
+                }
+                return cfg.Dump(os.Stdout)
+            },
+        },
+    },
+}
+
+// configFlags turns the flags of the config package into global CLI flags.
+func configFlags() []cli.Flag {
+    flags := []cli.Flag{
+        &cli.StringFlag{Name: "config", Usage: "path to a YAML configuration file (env " + config.FileEnv + ")"},
+    }
+
+    for _, f := range config.Flags() {
+        if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+            value, _ := strconv.ParseBool(f.DefValue)
+            flags = append(flags, &cli.BoolFlag{Name: f.Name, Usage: f.Usage, Value: value})
+            continue
+        }
+        flags = append(flags, &cli.StringFlag{Name: f.Name, Usage: f.Usage, Value: f.DefValue})
+    }
+    return flags
+}
+
+// loadConfig loads the configuration with the global flags given on the
+// command line and sets up logging according to it. Every command goes
+// through it.
+func loadConfig(cCtx *cli.Context) (*config.Config, error) {
+    flags := make(map[string]string)
+    for _, f := range config.Flags() {
+        if cCtx.IsSet(f.Name) {
+            flags[f.Name] = fmt.Sprint(cCtx.Value(f.Name))
+        }
+    }
+
+    cfg, err := config.Load(cCtx.String("config"), flags, os.LookupEnv)
+    if err != nil {
+        return nil, err
+    }
+
+    // Everything written through the log package goes to slog at info level,
+    // so cfg.Log.Level filters it too.
+    slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: cfg.Log.Level})))
+
+    return cfg, nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "time"
+
+    "todo-golang/internal/config"
+    "todo-golang/internal/model"
+    "todo-golang/storage"
+
+    "github.com/urfave/cli/v2"
+)
+
+// dumpVersion is the version of the export format written by export and
+// accepted by import.
+const dumpVersion = 1
+
+// exportBatch is how many tasks export reads per query, so that each query
+// stays within the query timeout.
+const exportBatch = 500
+
+// dump is the JSON document written by export and read by import. Tasks in
+// the trash are not exported.
+type dump struct {
+    Version    int             `json:"version"`
+    ExportedAt time.Time       `json:"exported_at"`
+    Projects   []model.Project `json:"projects"`
+    Tasks      []model.Task    `json:"tasks"`
+}
+
+var exportCommand = &cli.Command{
+    Name:  "export",
+    Usage: "write all projects and tasks as JSON",
+    Flags: []cli.Flag{
+        &cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "file to write to (default: standard output)"},
+    },
+    Action: func(cCtx *cli.Context) error {
+        return withBackend(cCtx, func(cfg *config.Config, b *backend) error {
+            d, err := exportDump(cCtx.Context, b)
+            if err != nil {
+                return err
+            }
+
+            out := io.Writer(os.Stdout)
+            if path := cCtx.String("output"); path != "" {
+                f, err := os.Create(path)
+                if err != nil {
+                    return err
+                }
+                defer f.Close()
+                out = f
+            }
+
+            enc := json.NewEncoder(out)
+            enc.SetIndent("", "  ")
+            return enc.Encode(d)
+        })
+    },
+}
+
+var importCommand = &cli.Command{
+    Name:      "import",
+    Usage:     "add the projects and tasks of an export to the database",
+    ArgsUsage: "[file]",
+    Description: "Projects and tasks are created anew, so they get new IDs and creation times; " +
+        "project, parent and blocker references are translated to the new IDs. " +
+        "References are checked before anything is written, but the import is not a single transaction: " +
+        "if a write fails, the projects and tasks imported before it stay and have to be removed by hand. " +
+        "The file is read from standard input if it is omitted or \"-\".",
+    Action: func(cCtx *cli.Context) error {
+        in := io.Reader(os.Stdin)
+        if path := cCtx.Args().First(); path != "" && path != "-" {
+            f, err := os.Open(path)
+            if err != nil {
+                return err
+            }
+            defer f.Close()
+            in = f
+        }
+
+        var d dump
+        if err := json.NewDecoder(in).Decode(&d); err != nil {
+            return fmt.Errorf("failed to read export: %w", err)
+        }
+        if d.Version != dumpVersion {
+            return fmt.Errorf("unsupported export version %d, expected %d", d.Version, dumpVersion)
+        }
+
+        return withBackend(cCtx, func(cfg *config.Config, b *backend) error {
+            if err := b.requirePersistent("import"); err != nil {
+                return err
+            }
+            if err := b.migrateUp(cCtx.Context); err != nil {
+                return fmt.Errorf("failed to migrate database: %w", err)
+            }
+            return importDump(cCtx.Context, b, d)
+        })
+    },
+}
+
+func exportDump(ctx context.Context, b *backend) (dump, error) {
+    d := dump{Version: dumpVersion, ExportedAt: time.Now().UTC(), Tasks: []model.Task{}}
+
+    projects, err := b.projects.GetProjects(ctx)
+    if err != nil {
+        return d, err
+    }
+    d.Projects = projects
+
+    page := storage.Page{Limit: exportBatch}
+    for {
+        tasks, err := b.repo.GetAll(ctx, page)
+        if err != nil {
+            return d, err
+        }
+        d.Tasks = append(d.Tasks, tasks...)
+
+        if len(tasks) < page.Limit {
+            return d, nil
+        }
+        page.After = []interface{}{tasks[len(tasks)-1].ID}
+    }
+}
+
+// checkDump returns an error if a task of d refers to a project, parent or
+// blocker that is not in d, or if parents form a cycle, so that import fails
+// before it writes anything.
+func checkDump(d dump) error {
+    projects := make(map[int]bool)
+    for _, p := range d.Projects {
+        projects[p.ID] = true
+    }
+    parents := make(map[int]*int)
+    for _, task := range d.Tasks {
+        parents[task.ID] = task.ParentID
+    }
+
+    for _, task := range d.Tasks {
+        if task.ProjectID != nil && !projects[*task.ProjectID] {
+            return fmt.Errorf("task %d refers to project %d, which is not in the export", task.ID, *task.ProjectID)
+        }
+        for _, blocker := range task.BlockedBy {
+            if _, ok := parents[blocker]; !ok {
+                return fmt.Errorf("task %d is blocked by task %d, which is not in the export", task.ID, blocker)
+            }
+        }
+
+        // A chain of parents longer than the number of tasks has a cycle.
+        id, depth := task.ID, 0
+        for parentID := task.ParentID; parentID != nil; parentID = parents[id] {
+            if _, ok := parents[*parentID]; !ok {
+                return fmt.Errorf("task %d refers to parent %d, which is not in the export", id, *parentID)
+            }
+            if depth++; depth > len(d.Tasks) {
+                return fmt.Errorf("task %d is its own ancestor", task.ID)
+            }
+            id = *parentID
+        }
+    }
+    return nil
+}
+
+// importDump adds the projects and tasks of d one by one. An error while
+// writing, such as a task the database rejects, leaves what was imported
+// before it in place.
+func importDump(ctx context.Context, b *backend, d dump) error {
+    if err := checkDump(d); err != nil {
+        return err
+    }
+
+    projectIDs := make(map[int]int)
+    for _, p := range d.Projects {
+        if p.DeletePolicy == "" {
+            p.DeletePolicy = model.ProjectDeleteBlock
+        }
+        created, err := b.projects.AddProject(ctx, model.Project{Name: p.Name, DeletePolicy: p.DeletePolicy})
+        if err != nil {
+            return fmt.Errorf("failed to import project %d: %w", p.ID, err)
+        }
+        projectIDs[p.ID] = created.ID
+    }
+
+    // Parents are imported before their subtasks; every pass imports the
+    // tasks whose parent is already in.
+    taskIDs := make(map[int]int)
+    pending := d.Tasks
+    for len(pending) > 0 {
+        var deferred []model.Task
+        for _, task := range pending {
+            if task.ParentID != nil {
+                parentID, ok := taskIDs[*task.ParentID]
+                if !ok {
+                    deferred = append(deferred, task)
+                    continue
+                }
+                task.ParentID = &parentID
+            }
+            if task.ProjectID != nil {
+                projectID := projectIDs[*task.ProjectID]
+                task.ProjectID = &projectID
+            }
+
+            created, err := b.repo.Add(ctx, task)
+            if err != nil {
+                return fmt.Errorf("failed to import task %d: %w", task.ID, err)
+            }
+            taskIDs[task.ID] = created.ID
+        }
+
+        pending = deferred
+    }
+
+    for _, task := range d.Tasks {
+        for _, blocker := range task.BlockedBy {
+            if err := b.repo.AddBlocker(ctx, taskIDs[task.ID], taskIDs[blocker]); err != nil {
+                return fmt.Errorf("failed to import blockers of task %d: %w", task.ID, err)
+            }
+        }
+    }
+
+    fmt.Printf("Imported %d projects and %d tasks\n", len(d.Projects), len(d.Tasks))
+    return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "strings"
+    "time"
+
+    "github.com/urfave/cli/v2"
+)
+
+var healthcheckCommand = &cli.Command{
+    Name:  "healthcheck",
+    Usage: "exit with status 0 if the server at the configured address is healthy",
+    Description: "Queries /healthz, which checks that the server can reach its storage. " +
+        "Meant for container health checks, e.g. HEALTHCHECK CMD [\"/todo-server\", \"healthcheck\"].",
+    Flags: []cli.Flag{
+        &cli.StringFlag{Name: "url", Usage: "base URL of the server (default: derived from the listen address)"},
+        &cli.DurationFlag{Name: "timeout", Value: 5 * time.Second, Usage: "how long to wait for the answer"},
+    },
+    Action: func(cCtx *cli.Context) error {
+        cfg, err := loadConfig(cCtx)
+        if err != nil {
+            return err
+        }
+
+        baseURL := cCtx.String("url")
+        if baseURL == "" {
+            baseURL = localURL(cfg.Server.Addr)
+        }
+
+        ctx, cancel := context.WithTimeout(cCtx.Context, cCtx.Duration("timeout"))
+        defer cancel()
+
+        req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(baseURL, "/")+"/healthz", nil)
+        if err != nil {
+            return err
+        }
+
+        resp, err := http.DefaultClient.Do(req)
+        if err != nil {
+            return fmt.Errorf("server is unreachable: %w", err)
+        }
+        defer resp.Body.Close()
+
+        if resp.StatusCode != http.StatusOK {
+            body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+            return fmt.Errorf("server is unhealthy: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+        }
+
+        fmt.Println("ok")
+        return nil
+    },
+}
+
+// localURL returns the URL at which the server listening on addr is reachable
+// from the same host.
+func localURL(addr string) string {
+    host, port, _ := net.SplitHostPort(addr)
+    if host == "" || host == "0.0.0.0" || host == "::" {
+        host = "127.0.0.1"
+    }
+    return "http://" + net.JoinHostPort(host, port)
+}
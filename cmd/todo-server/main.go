@@ -1,17 +1,13 @@
 package main
 
 import (
-    "log"
-    "net/http"
+    "fmt"
     "os"
+    _ "time/tzdata"
 
-    "todo-golang/internal/http-server/handlers"
-    "todo-golang/storage"
     _ "todo-golang/docs"
 
-    httpSwagger "github.com/swaggo/http-swagger"
-    "github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
+    "github.com/urfave/cli/v2"
 )
 
 // @title ToDo API
@@ -21,25 +17,31 @@ import (
 // @BasePath /
 
 func main() {
-    dsn := os.Getenv("DATABASE_URL")
-
-    db, err := storage.NewPostgresDB(dsn)
-    if err != nil {
-        log.Fatalf("Failed to connect to database: %v", err)
+    app := &cli.App{
+        Name:    "todo-server",
+        Usage:   "ToDo API server and maintenance tools",
+        Version: version,
+        // Every setting of the config package is a global flag, so it goes
+        // before the command: todo-server --addr :9090 serve.
+        Flags: configFlags(),
+        // Running the binary without a command starts the server, as before
+        // the commands existed.
+        DefaultCommand: "serve",
+        HideVersion:    true,
+        Commands: []*cli.Command{
+            serveCommand,
+            migrateCommand,
+            seedCommand,
+            exportCommand,
+            importCommand,
+            healthcheckCommand,
+            configCommand,
+            versionCommand,
+        },
     }
-    defer db.Close()
-
-
-    repo := storage.NewPostgresTaskRepository(db)
-
-    h := handlers.NewTaskHandler(repo)
 
-    r := chi.NewRouter()
-    r.Use(middleware.Logger)
-
-    r.Get("/docs/*", httpSwagger.WrapHandler)
-    h.SetupRoutes(r)
-
-    log.Println("Server is running on port 8080")
-    http.ListenAndServe(":8080", r)
-}
\ No newline at end of file
+    if err := app.Run(os.Args); err != nil {
+        fmt.Fprintf(os.Stderr, "todo-server: %v\n", err)
+        os.Exit(1)
+    }
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+    "fmt"
+    "os"
+    "strconv"
+    "text/tabwriter"
+    "time"
+
+    "todo-golang/internal/config"
+
+    "github.com/urfave/cli/v2"
+)
+
+var migrateCommand = &cli.Command{
+    Name:  "migrate",
+    Usage: "manage the database schema",
+    Subcommands: []*cli.Command{
+        {
+            Name:   "up",
+            Usage:  "apply every pending migration",
+            Action: migrateAction(migrateUp),
+        },
+        {
+            Name:      "down",
+            Usage:     "roll back the last applied migrations",
+            ArgsUsage: "[steps]",
+            Action:    migrateAction(migrateDown),
+        },
+        {
+            Name:   "status",
+            Usage:  "list the migrations and when they were applied",
+            Action: migrateAction(migrateStatus),
+        },
+    },
+}
+
+// migrateAction runs fn against the configured backend, which must have a schema.
+func migrateAction(fn func(cCtx *cli.Context, b *backend) error) cli.ActionFunc {
+    return func(cCtx *cli.Context) error {
+        return withBackend(cCtx, func(cfg *config.Config, b *backend) error {
+            if b.migrator == nil {
+                return fmt.Errorf("the selected storage backend has no schema to migrate")
+            }
+            return fn(cCtx, b)
+        })
+    }
+}
+
+func migrateUp(cCtx *cli.Context, b *backend) error {
+    return b.migrator.Up(cCtx.Context)
+}
+
+func migrateDown(cCtx *cli.Context, b *backend) error {
+    steps := 1
+    if cCtx.Args().Present() {
+        n, err := strconv.Atoi(cCtx.Args().First())
+        if err != nil || n < 1 {
+            return fmt.Errorf("invalid number of steps %q", cCtx.Args().First())
+        }
+        steps = n
+    }
+    return b.migrator.Down(cCtx.Context, steps)
+}
+
+func migrateStatus(cCtx *cli.Context, b *backend) error {
+    statuses, err := b.migrator.Status(cCtx.Context)
+    if err != nil {
+        return err
+    }
+
+    w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+    for _, s := range statuses {
+        appliedAt := "pending"
+        if s.Applied {
+            appliedAt = s.AppliedAt.Format(time.RFC3339)
+        }
+        fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+    }
+    return w.Flush()
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "todo-golang/internal/config"
+    "todo-golang/internal/model"
+    "todo-golang/storage"
+
+    "github.com/urfave/cli/v2"
+)
+
+var seedCommand = &cli.Command{
+    Name:  "seed",
+    Usage: "fill the database with sample projects and tasks for development",
+    Flags: []cli.Flag{
+        &cli.BoolFlag{Name: "force", Usage: "seed even if the database already has tasks"},
+    },
+    Action: func(cCtx *cli.Context) error {
+        return withBackend(cCtx, func(cfg *config.Config, b *backend) error {
+            if err := b.requirePersistent("seed"); err != nil {
+                return err
+            }
+            if err := b.migrateUp(cCtx.Context); err != nil {
+                return fmt.Errorf("failed to migrate database: %w", err)
+            }
+
+            existing, err := b.repo.GetAll(cCtx.Context, storage.Page{Limit: 1})
+            if err != nil {
+                return err
+            }
+            if len(existing) > 0 && !cCtx.Bool("force") {
+                return fmt.Errorf("the database already has tasks; use --force to seed anyway")
+            }
+
+            return seed(cCtx.Context, b, time.Now().UTC())
+        })
+    },
+}
+
+// seedTask is a sample task; parent and blocker refer to earlier entries of
+// seedTasks by index, and -1 means none.
+type seedTask struct {
+    title    string
+    project  string
+    priority model.Priority
+    tags     []string
+    due      time.Duration
+    done     bool
+    parent   int
+    blocker  int
+}
+
+var seedProjects = []string{"Work", "Home"}
+
+var seedTasks = []seedTask{
+    {title: "Prepare the quarterly report", project: "Work", priority: model.PriorityHigh, tags: []string{"report"}, due: 72 * time.Hour, parent: -1, blocker: -1},
+    {title: "Collect the sales figures", project: "Work", priority: model.PriorityNormal, tags: []string{"report"}, due: 24 * time.Hour, parent: 0, blocker: -1},
+    {title: "Draft the summary", project: "Work", priority: model.PriorityNormal, parent: 0, blocker: 1},
+    {title: "Fix the login timeout bug", project: "Work", priority: model.PriorityUrgent, tags: []string{"backend", "bug"}, due: 4 * time.Hour, parent: -1, blocker: -1},
+    {title: "Review the deploy checklist", project: "Work", priority: model.PriorityLow, tags: []string{"ops"}, done: true, parent: -1, blocker: -1},
+    {title: "Buy groceries", project: "Home", priority: model.PriorityNormal, tags: []string{"errands"}, due: 8 * time.Hour, parent: -1, blocker: -1},
+    {title: "Renew the car insurance", project: "Home", priority: model.PriorityHigh, due: 14 * 24 * time.Hour, parent: -1, blocker: -1},
+    {title: "Read a book", priority: model.PriorityLow, parent: -1, blocker: -1},
+}
+
+func seed(ctx context.Context, b *backend, now time.Time) error {
+    projectIDs := make(map[string]int)
+    for _, name := range seedProjects {
+        project, err := b.projects.AddProject(ctx, model.Project{Name: name, DeletePolicy: model.ProjectDeleteCascade})
+        if err != nil {
+            return err
+        }
+        projectIDs[name] = project.ID
+    }
+
+    taskIDs := make([]int, len(seedTasks))
+    for i, s := range seedTasks {
+        task := model.Task{Title: s.title, Priority: s.priority, Tags: s.tags, Done: s.done}
+        if s.project != "" {
+            id := projectIDs[s.project]
+            task.ProjectID = &id
+        }
+        if s.parent >= 0 {
+            task.ParentID = &taskIDs[s.parent]
+        }
+        if s.due > 0 {
+            due := now.Add(s.due).Truncate(time.Hour)
+            task.DueAt = &due
+        }
+
+        created, err := b.repo.Add(ctx, task)
+        if err != nil {
+            return err
+        }
+        taskIDs[i] = created.ID
+
+        if s.blocker >= 0 {
+            if err := b.repo.AddBlocker(ctx, created.ID, taskIDs[s.blocker]); err != nil {
+                return err
+            }
+        }
+    }
+
+    fmt.Printf("Seeded %d projects and %d tasks\n", len(seedProjects), len(seedTasks))
+    return nil
+}
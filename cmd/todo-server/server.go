@@ -0,0 +1,170 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "log/slog"
+    "net/http"
+    "os"
+    "os/signal"
+    "sync"
+    "syscall"
+    "time"
+
+    "todo-golang/internal/config"
+    "todo-golang/internal/http-server/handlers"
+    "todo-golang/internal/purge"
+    "todo-golang/internal/reminder"
+
+    httpSwagger "github.com/swaggo/http-swagger"
+    "github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+    "github.com/urfave/cli/v2"
+)
+
+var serveCommand = &cli.Command{
+    Name:  "serve",
+    Usage: "apply pending migrations and run the HTTP server",
+    Action: func(cCtx *cli.Context) error {
+        return withBackend(cCtx, runServer)
+    },
+}
+
+func runServer(cfg *config.Config, b *backend) error {
+    if err := b.migrateUp(context.Background()); err != nil {
+        return fmt.Errorf("failed to migrate database: %w", err)
+    }
+
+    ctx, kill, stop := shutdownSignals()
+    defer stop()
+
+    repo := b.repo
+    h := handlers.NewTaskHandler(repo)
+
+    var jobs sync.WaitGroup
+    if cfg.Features.Reminders {
+        var notifier reminder.Notifier = reminder.LogNotifier{}
+        if cfg.Reminders.Webhook != "" {
+            notifier = reminder.NewWebhookNotifier(cfg.Reminders.Webhook)
+        }
+        scheduler := reminder.NewScheduler(repo, notifier, cfg.Reminders.Interval)
+        jobs.Add(1)
+        go func() {
+            defer jobs.Done()
+            scheduler.Run(ctx)
+        }()
+    }
+
+    if cfg.Features.TrashPurge {
+        purger := purge.NewPurger(repo, cfg.Trash.Retention, cfg.Trash.PurgeInterval)
+        jobs.Add(1)
+        go func() {
+            defer jobs.Done()
+            purger.Run(ctx)
+        }()
+    }
+
+    r := chi.NewRouter()
+    if cfg.Log.Level <= slog.LevelInfo {
+        r.Use(middleware.Logger)
+    }
+
+    if cfg.Features.Swagger {
+        r.Get("/docs/*", httpSwagger.WrapHandler)
+    }
+    h.SetupRoutes(r)
+    handlers.NewProjectHandler(b.projects, h).SetupRoutes(r)
+
+    log.Printf("Server is listening on %s", cfg.Server.Addr)
+    err := serve(ctx, kill, newServer(cfg.Server, r), cfg.Server.ShutdownTimeout)
+
+    // withBackend closes the storage only once requests and background jobs
+    // are done with it.
+    jobs.Wait()
+    if err != nil {
+        return err
+    }
+
+    log.Println("Server stopped")
+    return nil
+}
+
+func newServer(cfg config.Server, handler http.Handler) *http.Server {
+    return &http.Server{
+        Addr:              cfg.Addr,
+        Handler:           handler,
+        ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+        ReadTimeout:       cfg.ReadTimeout,
+        WriteTimeout:      cfg.WriteTimeout,
+        IdleTimeout:       cfg.IdleTimeout,
+    }
+}
+
+// shutdownSignals returns a context that is cancelled on the first SIGINT or
+// SIGTERM and one that is cancelled on the second. Later signals get their
+// default action again.
+func shutdownSignals() (ctx, kill context.Context, stop func()) {
+    ctx, cancelCtx := context.WithCancel(context.Background())
+    kill, cancelKill := context.WithCancel(context.Background())
+
+    sigs := make(chan os.Signal, 1)
+    signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+    done := make(chan struct{})
+    go func() {
+        defer signal.Stop(sigs)
+        for _, cancel := range []context.CancelFunc{cancelCtx, cancelKill} {
+            select {
+            case <-sigs:
+                cancel()
+            case <-done:
+                return
+            }
+        }
+    }()
+
+    return ctx, kill, func() {
+        close(done)
+        cancelCtx()
+        cancelKill()
+    }
+}
+
+// serve runs srv until ctx is done and then shuts it down. It returns an error
+// if the server cannot listen or shutdown fails to drain it.
+func serve(ctx, kill context.Context, srv *http.Server, shutdownTimeout time.Duration) error {
+    errc := make(chan error, 1)
+    go func() {
+        errc <- srv.ListenAndServe()
+    }()
+
+    select {
+    case err := <-errc:
+        return fmt.Errorf("failed to serve on %s: %w", srv.Addr, err)
+    case <-ctx.Done():
+    }
+
+    log.Println("Shutting down server")
+    if err := shutdown(kill, srv, shutdownTimeout); err != nil {
+        return err
+    }
+    if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+        return fmt.Errorf("failed to serve on %s: %w", srv.Addr, err)
+    }
+    return nil
+}
+
+// shutdown stops srv from accepting connections and waits up to timeout for
+// in-flight requests to finish, or until kill is done. Connections still open
+// after that are closed and an error is returned.
+func shutdown(kill context.Context, srv *http.Server, timeout time.Duration) error {
+    ctx, cancel := context.WithTimeout(kill, timeout)
+    defer cancel()
+
+    if err := srv.Shutdown(ctx); err != nil {
+        srv.Close()
+        return fmt.Errorf("failed to drain connections: %w", err)
+    }
+    return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "syscall"
+    "testing"
+    "time"
+)
+
+// slowServer starts a server whose requests block until release is closed
+// and returns it with the result of one request sent to it. The request is
+// in flight when slowServer returns.
+func slowServer(t *testing.T) (ts *httptest.Server, release chan struct{}, result <-chan error) {
+    t.Helper()
+    release = make(chan struct{})
+    started := make(chan struct{})
+    ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        close(started)
+        <-release
+        w.WriteHeader(http.StatusNoContent)
+    }))
+    t.Cleanup(ts.Close)
+
+    errc := make(chan error, 1)
+    go func() {
+        resp, err := http.Get(ts.URL)
+        if err == nil {
+            resp.Body.Close()
+            if resp.StatusCode != http.StatusNoContent {
+                err = errors.New(resp.Status)
+            }
+        }
+        errc <- err
+    }()
+    <-started
+    return ts, release, errc
+}
+
+func TestShutdownDrainsRequests(t *testing.T) {
+    ts, release, result := slowServer(t)
+
+    done := make(chan error, 1)
+    go func() { done <- shutdown(context.Background(), ts.Config, time.Minute) }()
+
+    select {
+    case err := <-done:
+        t.Fatalf("shutdown returned %v with a request in flight", err)
+    case <-time.After(50 * time.Millisecond):
+    }
+    close(release)
+
+    if err := <-done; err != nil {
+        t.Errorf("shutdown: %v", err)
+    }
+    if err := <-result; err != nil {
+        t.Errorf("request in flight: %v", err)
+    }
+    if _, err := http.Get(ts.URL); err == nil {
+        t.Error("server accepts requests after shutdown")
+    }
+}
+
+func TestShutdownTimeout(t *testing.T) {
+    ts, release, result := slowServer(t)
+    defer close(release)
+
+    err := shutdown(context.Background(), ts.Config, 50*time.Millisecond)
+    if !errors.Is(err, context.DeadlineExceeded) {
+        t.Errorf("shutdown error = %v, want a deadline error", err)
+    }
+    if err := <-result; err == nil {
+        t.Error("request in flight completed, want its connection closed")
+    }
+}
+
+func TestShutdownKill(t *testing.T) {
+    ts, release, result := slowServer(t)
+    defer close(release)
+
+    kill, cancel := context.WithCancel(context.Background())
+    cancel()
+
+    start := time.Now()
+    err := shutdown(kill, ts.Config, time.Minute)
+    if !errors.Is(err, context.Canceled) {
+        t.Errorf("shutdown error = %v, want it cancelled", err)
+    }
+    if elapsed := time.Since(start); elapsed > 5*time.Second {
+        t.Errorf("shutdown took %s after kill", elapsed)
+    }
+    if err := <-result; err == nil {
+        t.Error("request in flight completed, want its connection closed")
+    }
+}
+
+func TestShutdownSignals(t *testing.T) {
+    ctx, kill, stop := shutdownSignals()
+    defer stop()
+
+    wait := func(ctx context.Context) bool {
+        select {
+        case <-ctx.Done():
+            return true
+        case <-time.After(5 * time.Second):
+            return false
+        }
+    }
+
+    if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+        t.Fatal(err)
+    }
+    if !wait(ctx) {
+        t.Fatal("first signal did not cancel ctx")
+    }
+    if kill.Err() != nil {
+        t.Fatal("first signal cancelled kill")
+    }
+
+    if err := syscall.Kill(syscall.Getpid(), syscall.SIGINT); err != nil {
+        t.Fatal(err)
+    }
+    if !wait(kill) {
+        t.Fatal("second signal did not cancel kill")
+    }
+}
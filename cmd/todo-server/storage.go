@@ -0,0 +1,117 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "log"
+
+    "todo-golang/internal/config"
+    "todo-golang/storage"
+
+    "github.com/urfave/cli/v2"
+)
+
+type backend struct {
+    repo     storage.TaskRepository
+    projects storage.ProjectRepository
+    // migrator is nil for backends without a schema, such as in-memory storage.
+    migrator *storage.Migrator
+    close    func()
+}
+
+func resolveStorageKind(kind, dsn string) string {
+    if kind != "" {
+        return kind
+    }
+
+    switch {
+    case dsn == "":
+        return "memory"
+    case storage.IsSQLiteDSN(dsn):
+        return "sqlite"
+    default:
+        return "postgres"
+    }
+}
+
+// openBackend opens the storage backend selected by cfg, inferring its kind
+// from the URL if it is not given.
+func openBackend(cfg config.Database) (*backend, error) {
+    switch resolveStorageKind(cfg.Storage, cfg.URL) {
+    case "memory":
+        log.Println("Using in-memory task storage")
+        repo := storage.NewMemoryTaskRepository()
+        return &backend{repo: repo, projects: repo, close: func() {}}, nil
+    case "postgres":
+        db, err := storage.NewPostgresDB(cfg.URL, storage.PoolConfig{
+            MaxConns:        int32(cfg.MaxConns),
+            MinConns:        int32(cfg.MinConns),
+            MaxConnLifetime: cfg.MaxConnLifetime,
+            MaxConnIdleTime: cfg.MaxConnIdleTime,
+        })
+        if err != nil {
+            return nil, fmt.Errorf("failed to connect to database: %w", err)
+        }
+
+        migrator, err := storage.NewPostgresMigrator(db)
+        if err != nil {
+            db.Close()
+            return nil, err
+        }
+
+        repo := storage.NewPostgresTaskRepository(db, cfg.QueryTimeout)
+        return &backend{repo: repo, projects: repo, migrator: migrator, close: db.Close}, nil
+    case "sqlite":
+        db, err := storage.NewSQLiteDB(cfg.URL)
+        if err != nil {
+            return nil, fmt.Errorf("failed to open database: %w", err)
+        }
+
+        migrator, err := storage.NewSQLiteMigrator(db)
+        if err != nil {
+            db.Close()
+            return nil, err
+        }
+
+        repo := storage.NewSQLiteTaskRepository(db, cfg.QueryTimeout)
+        return &backend{repo: repo, projects: repo, migrator: migrator, close: func() { db.Close() }}, nil
+    default:
+        return nil, fmt.Errorf("unknown storage backend %q", cfg.Storage)
+    }
+}
+
+// migrateUp brings the schema up to date before the server starts accepting requests.
+func (b *backend) migrateUp(ctx context.Context) error {
+    if b.migrator == nil {
+        return nil
+    }
+
+    return b.migrator.Up(ctx)
+}
+
+// withBackend loads the configuration, opens the storage it selects and runs
+// fn, closing the storage once fn returns. Every command that needs storage
+// goes through it.
+func withBackend(cCtx *cli.Context, fn func(cfg *config.Config, b *backend) error) error {
+    cfg, err := loadConfig(cCtx)
+    if err != nil {
+        return err
+    }
+
+    b, err := openBackend(cfg.Database)
+    if err != nil {
+        return fmt.Errorf("failed to initialise storage: %w", err)
+    }
+    defer b.close()
+
+    return fn(cfg, b)
+}
+
+// requirePersistent rejects commands that would write to storage that is lost
+// when the process exits.
+func (b *backend) requirePersistent(command string) error {
+    if b.migrator == nil {
+        return fmt.Errorf("%s has no effect on in-memory storage; set a database URL", command)
+    }
+    return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+    "fmt"
+    "runtime"
+    "runtime/debug"
+
+    "github.com/urfave/cli/v2"
+)
+
+// version is set at build time with -ldflags "-X main.version=v1.2.3".
+var version = "dev"
+
+var versionCommand = &cli.Command{
+    Name:  "version",
+    Usage: "print the version of the binary",
+    Action: func(cCtx *cli.Context) error {
+        fmt.Printf("todo-server %s (commit %s, %s)\n", version, commit(), runtime.Version())
+        return nil
+    },
+}
+
+// commit returns the VCS revision the binary was built from, as recorded by
+// the go command.
+func commit() string {
+    info, ok := debug.ReadBuildInfo()
+    if !ok {
+        return "unknown"
+    }
+
+    revision, modified := "unknown", false
+    for _, s := range info.Settings {
+        switch s.Key {
+        case "vcs.revision":
+            revision = s.Value
+        case "vcs.modified":
+            modified = s.Value == "true"
+        }
+    }
+
+    if len(revision) > 12 {
+        revision = revision[:12]
+    }
+    if modified {
+        revision += "-dirty"
+    }
+    return revision
+}
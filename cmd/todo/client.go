@@ -0,0 +1,122 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "strings"
+
+    "todo-golang/internal/model"
+)
+
+// client calls the ToDo REST API.
+type client struct {
+    baseURL string
+    // token is sent as a bearer token, for servers behind an authenticating proxy.
+    token   string
+    // user is sent in X-User and recorded in the history of status changes.
+    user    string
+    http    *http.Client
+}
+
+// taskPage mirrors handlers.TaskPage.
+type taskPage struct {
+    Items      []model.Task `json:"items"`
+    NextCursor string       `json:"next_cursor"`
+}
+
+// apiError is an unsuccessful answer of the server, which explains the
+// failure in a plain-text body.
+type apiError struct {
+    status  int
+    message string
+}
+
+func (e *apiError) Error() string {
+    return fmt.Sprintf("%d %s: %s", e.status, http.StatusText(e.status), e.message)
+}
+
+// do sends a request with body encoded as JSON, unless it is already a
+// json.RawMessage, and decodes the answer into out if it is not nil.
+func (c *client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+    u := strings.TrimSuffix(c.baseURL, "/") + path
+    if len(query) > 0 {
+        u += "?" + query.Encode()
+    }
+
+    var reqBody io.Reader
+    if body != nil {
+        raw, ok := body.(json.RawMessage)
+        if !ok {
+            var err error
+            if raw, err = json.Marshal(body); err != nil {
+                return err
+            }
+        }
+        reqBody = bytes.NewReader(raw)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+    if err != nil {
+        return err
+    }
+    if body != nil {
+        req.Header.Set("Content-Type", "application/json")
+    }
+    req.Header.Set("Accept", "application/json")
+    if c.token != "" {
+        req.Header.Set("Authorization", "Bearer "+c.token)
+    }
+    if c.user != "" {
+        req.Header.Set("X-User", c.user)
+    }
+
+    resp, err := c.http.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode >= 300 {
+        msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+        return &apiError{status: resp.StatusCode, message: strings.TrimSpace(string(msg))}
+    }
+
+    if out == nil {
+        return nil
+    }
+    if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+        return fmt.Errorf("failed to decode response of %s %s: %w", method, path, err)
+    }
+    return nil
+}
+
+// listTasks fetches a paginated task listing. It follows next_cursor through
+// every page if all is set and returns the cursor of the next page otherwise.
+func (c *client) listTasks(ctx context.Context, path string, query url.Values, all bool) ([]model.Task, string, error) {
+    if query == nil {
+        query = url.Values{}
+    }
+
+    tasks := []model.Task{}
+    for {
+        var page taskPage
+        if err := c.do(ctx, http.MethodGet, path, query, nil, &page); err != nil {
+            return nil, "", err
+        }
+        tasks = append(tasks, page.Items...)
+
+        if !all || page.NextCursor == "" {
+            return tasks, page.NextCursor, nil
+        }
+        query.Set("cursor", page.NextCursor)
+    }
+}
+
+func taskPath(id int, rest ...string) string {
+    return fmt.Sprintf("/tasks/%d", id) + strings.Join(rest, "")
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+    "fmt"
+
+    "github.com/urfave/cli/v2"
+)
+
+// The bash and zsh scripts ask the binary itself for the candidates through
+// the hidden --generate-bash-completion flag of urfave/cli.
+const bashCompletion = `_todo_complete() {
+    local cur opts
+    COMPREPLY=()
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    if [[ "$cur" == -* ]]; then
+        opts=$("${COMP_WORDS[@]:0:$COMP_CWORD}" "$cur" --generate-bash-completion 2>/dev/null)
+    else
+        opts=$("${COMP_WORDS[@]:0:$COMP_CWORD}" --generate-bash-completion 2>/dev/null)
+    fi
+    COMPREPLY=($(compgen -W "$opts" -- "$cur"))
+}
+complete -o bashdefault -o default -F _todo_complete todo
+`
+
+const zshCompletion = `#compdef todo
+
+_todo() {
+    local -a opts
+    local cur=${words[-1]}
+    if [[ "$cur" == -* ]]; then
+        opts=("${(@f)$(${words[@]:0:#words[@]-1} "$cur" --generate-bash-completion 2>/dev/null)}")
+    else
+        opts=("${(@f)$(${words[@]:0:#words[@]-1} --generate-bash-completion 2>/dev/null)}")
+    fi
+
+    if [[ -n "${opts[1]}" ]]; then
+        _describe 'values' opts
+    else
+        _files
+    fi
+}
+
+compdef _todo todo
+`
+
+var completionCommand = &cli.Command{
+    Name:      "completion",
+    Usage:     "print a shell completion script",
+    ArgsUsage: "bash|zsh|fish",
+    Description: "Load the script in the shell's startup file, e.g.\n" +
+        "   bash: source <(todo completion bash)\n" +
+        "   zsh:  source <(todo completion zsh)\n" +
+        "   fish: todo completion fish | source",
+    Action: func(cCtx *cli.Context) error {
+        switch shell := cCtx.Args().First(); shell {
+        case "bash":
+            fmt.Print(bashCompletion)
+        case "zsh":
+            fmt.Print(zshCompletion)
+        case "fish":
+            script, err := cCtx.App.ToFishCompletion()
+            if err != nil {
+                return err
+            }
+            fmt.Print(script)
+        default:
+            return fmt.Errorf("unsupported shell %q: use bash, zsh or fish", shell)
+        }
+        return nil
+    },
+}
@@ -0,0 +1,195 @@
+// Command todo is a terminal client for the ToDo REST API.
+package main
+
+import (
+    "fmt"
+    "net/http"
+    "os"
+    "slices"
+    "strings"
+    "time"
+
+    "github.com/urfave/cli/v2"
+)
+
+func main() {
+    app := &cli.App{
+        Name:                 "todo",
+        Usage:                "manage tasks on a ToDo server",
+        EnableBashCompletion: true,
+        Flags:                globalFlags(true),
+        Commands: []*cli.Command{
+            lsCommand,
+            dueCommand,
+            showCommand,
+            addCommand,
+            editCommand,
+            replaceCommand,
+            doneCommand,
+            undoneCommand,
+            rmCommand,
+            restoreCommand,
+            trashCommand,
+            subtasksCommand,
+            historyCommand,
+            occurrencesCommand,
+            tagCommand,
+            untagCommand,
+            tagsCommand,
+            blockCommand,
+            unblockCommand,
+            healthCommand,
+            completionCommand,
+        },
+    }
+
+    // The global flags are accepted after the command name as well, as in
+    // todo ls -o json.
+    for _, cmd := range app.Commands {
+        cmd.Flags = append(cmd.Flags, globalFlags(false)...)
+        cmd.Before = checkOutput
+    }
+
+    if err := app.Run(flagsFirst(app, os.Args)); err != nil {
+        fmt.Fprintf(os.Stderr, "todo: %v\n", err)
+        os.Exit(1)
+    }
+}
+
+// globalFlags returns the flags shared by all commands. Only the copies on the
+// application read the environment, so that a flag given before the command
+// name is not overridden by the environment variable on the command.
+func globalFlags(env bool) []cli.Flag {
+    envVars := func(names ...string) []string {
+        if !env {
+            return nil
+        }
+        return names
+    }
+
+    return []cli.Flag{
+        &cli.StringFlag{Name: "server", Aliases: []string{"s"}, Value: "http://localhost:8080", EnvVars: envVars("TODO_SERVER"), Usage: "base URL of the ToDo API"},
+        &cli.StringFlag{Name: "token", EnvVars: envVars("TODO_TOKEN"), Usage: "bearer token sent with every request"},
+        &cli.StringFlag{Name: "user", EnvVars: envVars("TODO_USER", "USER"), Usage: "name recorded in the history of status changes"},
+        &cli.StringFlag{Name: "output", Aliases: []string{"o"}, Value: outputTable, EnvVars: envVars("TODO_OUTPUT"), Usage: "output format: " + strings.Join(outputModes, ", ")},
+        &cli.DurationFlag{Name: "timeout", Value: 30 * time.Second, Usage: "how long to wait for each request"},
+    }
+}
+
+// flagsFirst moves the flags given among the arguments of a command in front
+// of them, since urfave/cli stops parsing flags at the first argument:
+// todo add Buy milk -p high is run as todo add -p high -- Buy milk. Arguments
+// after "--" are kept as they are.
+func flagsFirst(app *cli.App, args []string) []string {
+    if len(args) == 0 || args[len(args)-1] == "--generate-bash-completion" {
+        return args
+    }
+
+    i := skipFlags(app.Flags, args, 1)
+    if i >= len(args) {
+        return args
+    }
+    cmd := app.Command(args[i])
+    if cmd == nil {
+        return args
+    }
+
+    var flags, rest []string
+    for j := i + 1; j < len(args); j++ {
+        arg := args[j]
+        switch {
+        case arg == "--":
+            rest = append(rest, args[j+1:]...)
+            j = len(args)
+        case isFlag(arg):
+            flags = append(flags, arg)
+            if takesValue(cmd.Flags, arg) && j+1 < len(args) {
+                j++
+                flags = append(flags, args[j])
+            }
+        default:
+            rest = append(rest, arg)
+        }
+    }
+
+    reordered := append(slices.Clone(args[:i+1]), flags...)
+    if len(rest) > 0 {
+        reordered = append(append(reordered, "--"), rest...)
+    }
+    return reordered
+}
+
+// skipFlags returns the index of the first argument from i on that is not one
+// of flags or its value.
+func skipFlags(flags []cli.Flag, args []string, i int) int {
+    for i < len(args) && isFlag(args[i]) && args[i] != "--" {
+        if takesValue(flags, args[i]) {
+            i++
+        }
+        i++
+    }
+    return i
+}
+
+func isFlag(arg string) bool {
+    return len(arg) > 1 && arg[0] == '-'
+}
+
+// takesValue reports whether arg is a flag of flags whose value is the next
+// argument. Unknown flags are left to urfave/cli to reject.
+func takesValue(flags []cli.Flag, arg string) bool {
+    name := strings.TrimLeft(arg, "-")
+    if strings.Contains(name, "=") {
+        return false
+    }
+    for _, f := range flags {
+        if slices.Contains(f.Names(), name) {
+            v, ok := f.(interface{ TakesValue() bool })
+            return ok && v.TakesValue()
+        }
+    }
+    return false
+}
+
+// global returns the value of a global flag, preferring the copy given after
+// the command name, then the one given before it or in the environment.
+func global(cCtx *cli.Context, name string) interface{} {
+    lineage := cCtx.Lineage()
+    for _, c := range lineage {
+        if c.IsSet(name) {
+            return c.Value(name)
+        }
+    }
+    return cCtx.Value(name)
+}
+
+func checkOutput(cCtx *cli.Context) error {
+    if mode := global(cCtx, "output").(string); !slices.Contains(outputModes, mode) {
+        return fmt.Errorf("unknown output format %q: use %s", mode, strings.Join(outputModes, ", "))
+    }
+    return nil
+}
+
+func apiClient(cCtx *cli.Context) *client {
+    return &client{
+        baseURL: global(cCtx, "server").(string),
+        token:   global(cCtx, "token").(string),
+        user:    global(cCtx, "user").(string),
+        http:    &http.Client{Timeout: global(cCtx, "timeout").(time.Duration)},
+    }
+}
+
+func output(cCtx *cli.Context) printer {
+    return printer{mode: global(cCtx, "output").(string), w: os.Stdout}
+}
+
+var healthCommand = &cli.Command{
+    Name:  "health",
+    Usage: "check that the server is up and can reach its storage",
+    Action: func(cCtx *cli.Context) error {
+        if err := apiClient(cCtx).do(cCtx.Context, http.MethodGet, "/healthz", nil, nil, nil); err != nil {
+            return err
+        }
+        return output(cCtx).status("ok")
+    },
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+    "text/tabwriter"
+    "time"
+
+    "todo-golang/internal/model"
+)
+
+// Output modes. Table aligns columns under a header for reading; plain writes
+// the same rows tab-separated without a header, for scripts; JSON writes the
+// API objects.
+const (
+    outputTable = "table"
+    outputPlain = "plain"
+    outputJSON  = "json"
+)
+
+var outputModes = []string{outputTable, outputPlain, outputJSON}
+
+const timeLayout = "2006-01-02 15:04"
+
+type printer struct {
+    mode string
+    w    io.Writer
+}
+
+func (p printer) json(v interface{}) error {
+    enc := json.NewEncoder(p.w)
+    enc.SetIndent("", "  ")
+    return enc.Encode(v)
+}
+
+// rows writes a table in table and plain modes.
+func (p printer) rows(header []string, rows [][]string) error {
+    if p.mode == outputPlain {
+        for _, row := range rows {
+            fmt.Fprintln(p.w, strings.Join(row, "\t"))
+        }
+        return nil
+    }
+
+    tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(tw, strings.Join(header, "\t"))
+    for _, row := range rows {
+        fmt.Fprintln(tw, strings.Join(row, "\t"))
+    }
+    return tw.Flush()
+}
+
+var taskHeader = []string{"ID", "STATUS", "PRIORITY", "DUE", "PROJECT", "TITLE", "TAGS"}
+
+func taskRow(t model.Task, indent int) []string {
+    return []string{
+        strconv.Itoa(t.ID),
+        taskStatus(t),
+        t.Priority.String(),
+        formatTime(t.DueAt),
+        formatID(t.ProjectID),
+        strings.Repeat("  ", indent) + t.Title,
+        strings.Join(t.Tags, ","),
+    }
+}
+
+func taskStatus(t model.Task) string {
+    switch {
+    case t.DeletedAt != nil:
+        return "deleted"
+    case t.Done:
+        return "done"
+    case t.Blocked:
+        return "blocked"
+    default:
+        return "open"
+    }
+}
+
+func (p printer) tasks(tasks []model.Task) error {
+    if p.mode == outputJSON {
+        return p.json(tasks)
+    }
+
+    rows := make([][]string, 0, len(tasks))
+    for _, t := range tasks {
+        rows = append(rows, taskRow(t, 0))
+    }
+    return p.rows(taskHeader, rows)
+}
+
+// tree lists a task and its subtasks, indenting the titles by depth.
+func (p printer) tree(tree model.TaskTree) error {
+    if p.mode == outputJSON {
+        return p.json(tree)
+    }
+
+    var rows [][]string
+    var walk func(t model.TaskTree, depth int)
+    walk = func(t model.TaskTree, depth int) {
+        rows = append(rows, taskRow(t.Task, depth))
+        for _, sub := range t.Subtasks {
+            walk(sub, depth+1)
+        }
+    }
+    walk(tree, 0)
+    return p.rows(taskHeader, rows)
+}
+
+// task shows every field of one task: one field per line in table mode and a
+// single row in plain mode.
+func (p printer) task(t model.Task) error {
+    switch p.mode {
+    case outputJSON:
+        return p.json(t)
+    case outputPlain:
+        return p.rows(taskHeader, [][]string{taskRow(t, 0)})
+    }
+
+    blockedBy := make([]string, 0, len(t.BlockedBy))
+    for _, id := range t.BlockedBy {
+        blockedBy = append(blockedBy, strconv.Itoa(id))
+    }
+
+    tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+    for _, field := range [][2]string{
+        {"ID", strconv.Itoa(t.ID)},
+        {"Title", t.Title},
+        {"Status", taskStatus(t)},
+        {"Priority", t.Priority.String()},
+        {"Due", formatTime(t.DueAt)},
+        {"Recurrence", t.Recurrence},
+        {"Project", formatID(t.ProjectID)},
+        {"Parent", formatID(t.ParentID)},
+        {"Tags", strings.Join(t.Tags, ", ")},
+        {"Blocked by", strings.Join(blockedBy, ", ")},
+        {"Created", formatTime(&t.CreatedAt)},
+        {"Updated", formatTime(&t.UpdatedAt)},
+        {"Completed", formatTime(t.CompletedAt)},
+        {"Deleted", formatTime(t.DeletedAt)},
+    } {
+        if field[1] != "" {
+            fmt.Fprintf(tw, "%s:\t%s\n", field[0], field[1])
+        }
+    }
+    if err := tw.Flush(); err != nil {
+        return err
+    }
+
+    if t.Description != "" {
+        fmt.Fprintf(p.w, "\n%s\n", strings.TrimRight(t.Description, "\n"))
+    }
+    return nil
+}
+
+func (p printer) events(events []model.TaskEvent) error {
+    if p.mode == outputJSON {
+        return p.json(events)
+    }
+
+    rows := make([][]string, 0, len(events))
+    for _, e := range events {
+        rows = append(rows, []string{formatTime(&e.CreatedAt), e.Action, e.Actor})
+    }
+    return p.rows([]string{"TIME", "ACTION", "ACTOR"}, rows)
+}
+
+func (p printer) tags(tags []model.TagCount) error {
+    if p.mode == outputJSON {
+        return p.json(tags)
+    }
+
+    rows := make([][]string, 0, len(tags))
+    for _, t := range tags {
+        rows = append(rows, []string{t.Name, strconv.Itoa(t.Count)})
+    }
+    return p.rows([]string{"TAG", "TASKS"}, rows)
+}
+
+func (p printer) occurrences(occurrences []model.Occurrence) error {
+    if p.mode == outputJSON {
+        return p.json(occurrences)
+    }
+
+    rows := make([][]string, 0, len(occurrences))
+    for _, o := range occurrences {
+        rows = append(rows, []string{formatTime(&o.DueAt)})
+    }
+    return p.rows([]string{"DUE"}, rows)
+}
+
+// status reports the outcome of a command the server answers without a body.
+// JSON mode prints nothing, leaving the exit status as the only result.
+func (p printer) status(format string, args ...interface{}) error {
+    if p.mode == outputJSON {
+        return nil
+    }
+    _, err := fmt.Fprintf(p.w, format+"\n", args...)
+    return err
+}
+
+// formatTime shows t in the local time zone.
+func formatTime(t *time.Time) string {
+    if t == nil {
+        return ""
+    }
+    return t.Local().Format(timeLayout)
+}
+
+func formatID(id *int) string {
+    if id == nil {
+        return ""
+    }
+    return strconv.Itoa(*id)
+}
@@ -0,0 +1,567 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "os"
+    "slices"
+    "strconv"
+    "strings"
+    "time"
+
+    "todo-golang/internal/model"
+
+    "github.com/urfave/cli/v2"
+)
+
+// listFlags are shared by the commands wrapping paginated listings.
+func listFlags(tags bool) []cli.Flag {
+    flags := []cli.Flag{
+        &cli.StringFlag{Name: "query", Aliases: []string{"q"}, Usage: "filter expression, e.g. 'priority>=high title~deploy'"},
+        &cli.StringFlag{Name: "sort", Usage: "sort keys separated by commas, '-' for descending, e.g. -priority,due_at"},
+        &cli.IntFlag{Name: "limit", Usage: "page size (1-500, default: the server's)"},
+        &cli.BoolFlag{Name: "all", Aliases: []string{"a"}, Usage: "fetch every page instead of the first one"},
+    }
+    if tags {
+        flags = append(flags,
+            &cli.StringSliceFlag{Name: "tag", Aliases: []string{"t"}, Usage: "only tasks with all these tags"},
+            &cli.StringSliceFlag{Name: "any-tag", Usage: "only tasks with at least one of these tags"},
+        )
+    }
+    return flags
+}
+
+func listQuery(cCtx *cli.Context) url.Values {
+    query := url.Values{}
+    setIfSet(cCtx, query, "query", "q")
+    setIfSet(cCtx, query, "sort", "sort")
+    setIfSet(cCtx, query, "limit", "limit")
+    if tags := cCtx.StringSlice("tag"); len(tags) > 0 {
+        query.Set("tags_all", strings.Join(tags, ","))
+    }
+    if tags := cCtx.StringSlice("any-tag"); len(tags) > 0 {
+        query.Set("tags_any", strings.Join(tags, ","))
+    }
+    return query
+}
+
+// setIfSet copies a flag given on the command line to a query parameter.
+func setIfSet(cCtx *cli.Context, query url.Values, flag, param string) {
+    if cCtx.IsSet(flag) {
+        query.Set(param, fmt.Sprint(cCtx.Value(flag)))
+    }
+}
+
+// listAction wraps a paginated listing. pathFor returns the endpoint and may
+// add parameters of its own to the query.
+func listAction(pathFor func(cCtx *cli.Context, query url.Values) (string, error)) cli.ActionFunc {
+    return func(cCtx *cli.Context) error {
+        query := listQuery(cCtx)
+        path, err := pathFor(cCtx, query)
+        if err != nil {
+            return err
+        }
+
+        tasks, next, err := apiClient(cCtx).listTasks(cCtx.Context, path, query, cCtx.Bool("all"))
+        if err != nil {
+            return err
+        }
+
+        p := output(cCtx)
+        if err := p.tasks(tasks); err != nil {
+            return err
+        }
+        if next != "" && p.mode == outputTable {
+            fmt.Fprintln(os.Stderr, "More tasks are available; use --all to list them.")
+        }
+        return nil
+    }
+}
+
+var lsCommand = &cli.Command{
+    Name:    "ls",
+    Aliases: []string{"list"},
+    Usage:   "list tasks",
+    Flags: append(listFlags(true),
+        &cli.BoolFlag{Name: "done", Usage: "only done (--done) or open (--done=false) tasks"},
+        &cli.BoolFlag{Name: "overdue", Usage: "only overdue open tasks (--overdue) or all others (--overdue=false)"},
+    ),
+    Action: listAction(func(cCtx *cli.Context, query url.Values) (string, error) {
+        setIfSet(cCtx, query, "overdue", "overdue")
+        if cCtx.IsSet("done") {
+            setIfSet(cCtx, query, "done", "done")
+            return "/tasks/filter", nil
+        }
+        return "/tasks", nil
+    }),
+}
+
+var dueCommand = &cli.Command{
+    Name:  "due",
+    Usage: "list open tasks due soon, nearest first",
+    Flags: append(listFlags(true),
+        &cli.DurationFlag{Name: "within", Usage: "how far ahead to look (default: 24h)"},
+    ),
+    Action: listAction(func(cCtx *cli.Context, query url.Values) (string, error) {
+        setIfSet(cCtx, query, "within", "within")
+        return "/tasks/due", nil
+    }),
+}
+
+var subtasksCommand = &cli.Command{
+    Name:      "subtasks",
+    Usage:     "list the direct subtasks of a task",
+    ArgsUsage: "ID",
+    Flags:     listFlags(false),
+    Action: listAction(func(cCtx *cli.Context, query url.Values) (string, error) {
+        id, err := argID(cCtx, 0)
+        if err != nil {
+            return "", err
+        }
+        return taskPath(id, "/subtasks"), nil
+    }),
+}
+
+var trashCommand = &cli.Command{
+    Name:   "trash",
+    Usage:  "list deleted tasks, most recently deleted first",
+    Flags:  listFlags(false),
+    Action: listAction(func(cCtx *cli.Context, query url.Values) (string, error) { return "/trash", nil }),
+}
+
+var showCommand = &cli.Command{
+    Name:      "show",
+    Usage:     "show a task",
+    ArgsUsage: "ID",
+    Flags: []cli.Flag{
+        &cli.BoolFlag{Name: "tree", Usage: "include the subtasks, to any depth"},
+    },
+    Action: func(cCtx *cli.Context) error {
+        id, err := argID(cCtx, 0)
+        if err != nil {
+            return err
+        }
+
+        if !cCtx.Bool("tree") {
+            var task model.Task
+            if err := apiClient(cCtx).do(cCtx.Context, http.MethodGet, taskPath(id), nil, nil, &task); err != nil {
+                return err
+            }
+            return output(cCtx).task(task)
+        }
+
+        var tree model.TaskTree
+        query := url.Values{"expand": {"tree"}}
+        if err := apiClient(cCtx).do(cCtx.Context, http.MethodGet, taskPath(id), query, nil, &tree); err != nil {
+            return err
+        }
+        return output(cCtx).tree(tree)
+    },
+}
+
+// taskFlags set the fields of a task on add and edit.
+var taskFlags = []cli.Flag{
+    &cli.StringFlag{Name: "title", Usage: "new title"},
+    &cli.StringFlag{Name: "description", Aliases: []string{"d"}, Usage: "Markdown description"},
+    &cli.StringFlag{Name: "priority", Aliases: []string{"p"}, Usage: "low, normal, high or urgent"},
+    &cli.StringFlag{Name: "due", Usage: "due time: RFC 3339, 'YYYY-MM-DD HH:MM' or 'YYYY-MM-DD' in local time, or 'none'"},
+    &cli.StringFlag{Name: "recurrence", Aliases: []string{"r"}, Usage: "RRULE, e.g. FREQ=WEEKLY;BYDAY=MO;TZID=Europe/Moscow, or 'none'"},
+    &cli.StringFlag{Name: "project", Usage: "project ID, or 'none'"},
+    &cli.StringFlag{Name: "parent", Usage: "parent task ID, or 'none'"},
+    &cli.StringSliceFlag{Name: "tag", Aliases: []string{"t"}, Usage: "tag; repeat for several (replaces the task's tags on edit)"},
+}
+
+var addCommand = &cli.Command{
+    Name:      "add",
+    Usage:     "create a task",
+    ArgsUsage: "TITLE...",
+    Flags:     taskFlags[1:],
+    Action: func(cCtx *cli.Context) error {
+        title := strings.Join(cCtx.Args().Slice(), " ")
+        if strings.TrimSpace(title) == "" {
+            return fmt.Errorf("a title is required")
+        }
+
+        fields, err := taskFields(cCtx)
+        if err != nil {
+            return err
+        }
+        fields["title"] = title
+
+        var task model.Task
+        if err := apiClient(cCtx).do(cCtx.Context, http.MethodPost, "/tasks", nil, fields, &task); err != nil {
+            return err
+        }
+        return output(cCtx).task(task)
+    },
+}
+
+var editCommand = &cli.Command{
+    Name:      "edit",
+    Usage:     "change some fields of a task",
+    ArgsUsage: "ID",
+    Flags:     taskFlags,
+    Action: func(cCtx *cli.Context) error {
+        id, err := argID(cCtx, 0)
+        if err != nil {
+            return err
+        }
+
+        fields, err := taskFields(cCtx)
+        if err != nil {
+            return err
+        }
+        if len(fields) == 0 {
+            return fmt.Errorf("nothing to change; see todo edit --help")
+        }
+
+        var task model.Task
+        if err := apiClient(cCtx).do(cCtx.Context, http.MethodPatch, taskPath(id), nil, fields, &task); err != nil {
+            return err
+        }
+        return output(cCtx).task(task)
+    },
+}
+
+var replaceCommand = &cli.Command{
+    Name:      "replace",
+    Usage:     "replace a task with the JSON object in FILE (default: standard input)",
+    ArgsUsage: "ID [FILE]",
+    Action: func(cCtx *cli.Context) error {
+        id, err := argID(cCtx, 0)
+        if err != nil {
+            return err
+        }
+
+        in := io.Reader(os.Stdin)
+        if path := cCtx.Args().Get(1); path != "" && path != "-" {
+            f, err := os.Open(path)
+            if err != nil {
+                return err
+            }
+            defer f.Close()
+            in = f
+        }
+
+        var body json.RawMessage
+        if err := json.NewDecoder(in).Decode(&body); err != nil {
+            return fmt.Errorf("failed to read task: %w", err)
+        }
+
+        var task model.Task
+        if err := apiClient(cCtx).do(cCtx.Context, http.MethodPut, taskPath(id), nil, body, &task); err != nil {
+            return err
+        }
+        return output(cCtx).task(task)
+    },
+}
+
+var rmCommand = &cli.Command{
+    Name:      "rm",
+    Usage:     "move tasks to the trash with their subtasks",
+    ArgsUsage: "ID...",
+    Action: eachID(func(cCtx *cli.Context, id int) error {
+        if err := apiClient(cCtx).do(cCtx.Context, http.MethodDelete, taskPath(id), nil, nil, nil); err != nil {
+            return err
+        }
+        return output(cCtx).status("Task %d moved to trash", id)
+    }),
+}
+
+var restoreCommand = &cli.Command{
+    Name:      "restore",
+    Usage:     "take tasks out of the trash",
+    ArgsUsage: "ID...",
+    Action: eachID(func(cCtx *cli.Context, id int) error {
+        var task model.Task
+        if err := apiClient(cCtx).do(cCtx.Context, http.MethodPost, taskPath(id, "/restore"), nil, nil, &task); err != nil {
+            return err
+        }
+        return output(cCtx).task(task)
+    }),
+}
+
+var doneCommand = &cli.Command{
+    Name:      "done",
+    Usage:     "mark tasks done",
+    ArgsUsage: "ID...",
+    Flags: []cli.Flag{
+        &cli.BoolFlag{Name: "cascade", Usage: "also mark the open subtasks done"},
+        &cli.BoolFlag{Name: "force", Usage: "complete the task even if open tasks block it"},
+    },
+    Action: eachID(func(cCtx *cli.Context, id int) error {
+        query := url.Values{}
+        setIfSet(cCtx, query, "cascade", "cascade")
+        setIfSet(cCtx, query, "force", "force")
+        if err := apiClient(cCtx).do(cCtx.Context, http.MethodPatch, taskPath(id, "/done"), query, nil, nil); err != nil {
+            return err
+        }
+        return output(cCtx).status("Task %d marked done", id)
+    }),
+}
+
+var undoneCommand = &cli.Command{
+    Name:      "undone",
+    Aliases:   []string{"reopen"},
+    Usage:     "reopen done tasks",
+    ArgsUsage: "ID...",
+    Action: eachID(func(cCtx *cli.Context, id int) error {
+        if err := apiClient(cCtx).do(cCtx.Context, http.MethodPatch, taskPath(id, "/undone"), nil, nil, nil); err != nil {
+            return err
+        }
+        return output(cCtx).status("Task %d reopened", id)
+    }),
+}
+
+var historyCommand = &cli.Command{
+    Name:      "history",
+    Usage:     "show who changed the status of a task and when",
+    ArgsUsage: "ID",
+    Action: func(cCtx *cli.Context) error {
+        id, err := argID(cCtx, 0)
+        if err != nil {
+            return err
+        }
+
+        var events []model.TaskEvent
+        if err := apiClient(cCtx).do(cCtx.Context, http.MethodGet, taskPath(id, "/history"), nil, nil, &events); err != nil {
+            return err
+        }
+        return output(cCtx).events(events)
+    },
+}
+
+var occurrencesCommand = &cli.Command{
+    Name:      "occurrences",
+    Usage:     "preview the due times of a recurring task",
+    ArgsUsage: "ID",
+    Flags: []cli.Flag{
+        &cli.StringFlag{Name: "from", Usage: "start of the interval: YYYY-MM-DD or RFC 3339 (default: now)"},
+        &cli.StringFlag{Name: "to", Usage: "end of the interval (default: from + 30 days)"},
+    },
+    Action: func(cCtx *cli.Context) error {
+        id, err := argID(cCtx, 0)
+        if err != nil {
+            return err
+        }
+
+        query := url.Values{}
+        setIfSet(cCtx, query, "from", "from")
+        setIfSet(cCtx, query, "to", "to")
+
+        var occurrences []model.Occurrence
+        if err := apiClient(cCtx).do(cCtx.Context, http.MethodGet, taskPath(id, "/occurrences"), query, nil, &occurrences); err != nil {
+            return err
+        }
+        return output(cCtx).occurrences(occurrences)
+    },
+}
+
+var tagCommand = &cli.Command{
+    Name:      "tag",
+    Usage:     "add tags to a task",
+    ArgsUsage: "ID TAG...",
+    Action: func(cCtx *cli.Context) error {
+        id, tags, err := idAndNames(cCtx, "tag")
+        if err != nil {
+            return err
+        }
+
+        var task model.Task
+        for _, tag := range tags {
+            if err := apiClient(cCtx).do(cCtx.Context, http.MethodPost, taskPath(id, "/tags/", url.PathEscape(tag)), nil, nil, &task); err != nil {
+                return err
+            }
+        }
+        return output(cCtx).task(task)
+    },
+}
+
+var untagCommand = &cli.Command{
+    Name:      "untag",
+    Usage:     "remove tags from a task",
+    ArgsUsage: "ID TAG...",
+    Action: func(cCtx *cli.Context) error {
+        id, tags, err := idAndNames(cCtx, "tag")
+        if err != nil {
+            return err
+        }
+
+        for _, tag := range tags {
+            if err := apiClient(cCtx).do(cCtx.Context, http.MethodDelete, taskPath(id, "/tags/", url.PathEscape(tag)), nil, nil, nil); err != nil {
+                return err
+            }
+        }
+        return output(cCtx).status("Removed %s from task %d", strings.Join(tags, ", "), id)
+    },
+}
+
+var blockCommand = &cli.Command{
+    Name:      "block",
+    Usage:     "record that a task cannot be done before other tasks",
+    ArgsUsage: "ID BLOCKER_ID...",
+    Action: func(cCtx *cli.Context) error {
+        id, blockers, err := idAndNames(cCtx, "blocker ID")
+        if err != nil {
+            return err
+        }
+
+        var task model.Task
+        for _, blocker := range blockers {
+            if _, err := strconv.Atoi(blocker); err != nil {
+                return fmt.Errorf("invalid task ID %q", blocker)
+            }
+            if err := apiClient(cCtx).do(cCtx.Context, http.MethodPost, taskPath(id, "/blockers/", blocker), nil, nil, &task); err != nil {
+                return err
+            }
+        }
+        return output(cCtx).task(task)
+    },
+}
+
+var unblockCommand = &cli.Command{
+    Name:      "unblock",
+    Usage:     "remove dependencies of a task on other tasks",
+    ArgsUsage: "ID BLOCKER_ID...",
+    Action: func(cCtx *cli.Context) error {
+        id, blockers, err := idAndNames(cCtx, "blocker ID")
+        if err != nil {
+            return err
+        }
+
+        for _, blocker := range blockers {
+            if _, err := strconv.Atoi(blocker); err != nil {
+                return fmt.Errorf("invalid task ID %q", blocker)
+            }
+            if err := apiClient(cCtx).do(cCtx.Context, http.MethodDelete, taskPath(id, "/blockers/", blocker), nil, nil, nil); err != nil {
+                return err
+            }
+        }
+        return output(cCtx).status("Task %d is no longer blocked by %s", id, strings.Join(blockers, ", "))
+    },
+}
+
+var tagsCommand = &cli.Command{
+    Name:  "tags",
+    Usage: "list tags with the number of tasks carrying each",
+    Action: func(cCtx *cli.Context) error {
+        var tags []model.TagCount
+        if err := apiClient(cCtx).do(cCtx.Context, http.MethodGet, "/tags", nil, nil, &tags); err != nil {
+            return err
+        }
+        return output(cCtx).tags(tags)
+    },
+}
+
+// eachID runs fn for every task ID argument, stopping at the first failure.
+func eachID(fn func(cCtx *cli.Context, id int) error) cli.ActionFunc {
+    return func(cCtx *cli.Context) error {
+        if !cCtx.Args().Present() {
+            return fmt.Errorf("at least one task ID is required")
+        }
+        for i := 0; i < cCtx.Args().Len(); i++ {
+            id, err := argID(cCtx, i)
+            if err != nil {
+                return err
+            }
+            if err := fn(cCtx, id); err != nil {
+                return fmt.Errorf("task %d: %w", id, err)
+            }
+        }
+        return nil
+    }
+}
+
+func argID(cCtx *cli.Context, i int) (int, error) {
+    arg := cCtx.Args().Get(i)
+    if arg == "" {
+        return 0, fmt.Errorf("a task ID is required")
+    }
+    id, err := strconv.Atoi(arg)
+    if err != nil || id < 1 {
+        return 0, fmt.Errorf("invalid task ID %q", arg)
+    }
+    return id, nil
+}
+
+// idAndNames parses the arguments ID NAME..., requiring at least one name.
+func idAndNames(cCtx *cli.Context, what string) (int, []string, error) {
+    id, err := argID(cCtx, 0)
+    if err != nil {
+        return 0, nil, err
+    }
+    // The arguments keep the "--" that lets a name start with a dash.
+    names := slices.DeleteFunc(cCtx.Args().Tail(), func(name string) bool { return name == "--" })
+    if len(names) == 0 {
+        return 0, nil, fmt.Errorf("at least one %s is required", what)
+    }
+    return id, names, nil
+}
+
+// taskFields returns the task fields given as flags, in the JSON form the API
+// expects. "none" clears an optional field.
+func taskFields(cCtx *cli.Context) (map[string]interface{}, error) {
+    fields := make(map[string]interface{})
+
+    for _, name := range []string{"title", "description", "priority"} {
+        if cCtx.IsSet(name) {
+            fields[name] = cCtx.String(name)
+        }
+    }
+    if cCtx.IsSet("recurrence") {
+        recurrence := cCtx.String("recurrence")
+        if recurrence == "none" {
+            recurrence = ""
+        }
+        fields["recurrence"] = recurrence
+    }
+    if cCtx.IsSet("tag") {
+        fields["tags"] = cCtx.StringSlice("tag")
+    }
+
+    if cCtx.IsSet("due") {
+        due, err := parseDue(cCtx.String("due"))
+        if err != nil {
+            return nil, err
+        }
+        fields["due_at"] = due
+    }
+
+    for flag, field := range map[string]string{"project": "project_id", "parent": "parent_id"} {
+        if !cCtx.IsSet(flag) {
+            continue
+        }
+        value := cCtx.String(flag)
+        if value == "none" {
+            fields[field] = nil
+            continue
+        }
+        id, err := strconv.Atoi(value)
+        if err != nil {
+            return nil, fmt.Errorf("invalid %s ID %q", flag, value)
+        }
+        fields[field] = id
+    }
+
+    return fields, nil
+}
+
+// parseDue accepts RFC 3339 or a date with an optional time in the local time
+// zone. It returns nil for "none".
+func parseDue(s string) (*time.Time, error) {
+    if s == "none" {
+        return nil, nil
+    }
+    if t, err := time.Parse(time.RFC3339, s); err == nil {
+        return &t, nil
+    }
+    for _, layout := range []string{timeLayout, "2006-01-02"} {
+        if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+            return &t, nil
+        }
+    }
+    return nil, fmt.Errorf("invalid due time %q: use RFC 3339, 'YYYY-MM-DD HH:MM' or 'YYYY-MM-DD'", s)
+}
@@ -1,7 +1,214 @@
-package model
+// Package config holds the settings of todo-server. They are loaded from a
+// YAML file, environment variables and command-line flags, each source
+// overriding the previous one, and validated before the server starts.
+package config
 
-type Task struct {
-    ID     int    `json:"id"`
-    Title  string `json:"title"`
-    Done   bool   `json:"done"`
+import (
+    "errors"
+    "fmt"
+    "io"
+    "log/slog"
+    "net"
+    "net/url"
+    "regexp"
+    "time"
+
+    "gopkg.in/yaml.v3"
+)
+
+type Config struct {
+    Server    Server    `yaml:"server"`
+    Database  Database  `yaml:"database"`
+    Log       Log       `yaml:"log"`
+    Reminders Reminders `yaml:"reminders"`
+    Trash     Trash     `yaml:"trash"`
+    Features  Features  `yaml:"features"`
+}
+
+// Server configures the HTTP server. Zero read, write and idle timeouts mean
+// no timeout, as in net/http.
+type Server struct {
+    Addr              string        `yaml:"addr"`
+    ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+    ReadTimeout       time.Duration `yaml:"read_timeout"`
+    WriteTimeout      time.Duration `yaml:"write_timeout"`
+    IdleTimeout       time.Duration `yaml:"idle_timeout"`
+    // ShutdownTimeout bounds how long in-flight requests may take to finish
+    // after SIGINT or SIGTERM.
+    ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+}
+
+// Database selects the storage backend. The pool settings apply to
+// PostgreSQL only; SQLite always uses a single connection.
+type Database struct {
+    // Storage is postgres, sqlite or memory. If empty, it is inferred from URL.
+    Storage         string        `yaml:"storage"`
+    URL             string        `yaml:"url"`
+    // QueryTimeout bounds each storage operation; zero disables it.
+    QueryTimeout    time.Duration `yaml:"query_timeout"`
+    MaxConns        int           `yaml:"max_conns"`
+    MinConns        int           `yaml:"min_conns"`
+    MaxConnLifetime time.Duration `yaml:"max_conn_lifetime"`
+    MaxConnIdleTime time.Duration `yaml:"max_conn_idle_time"`
+}
+
+type Log struct {
+    // Level is debug, info, warn or error. Request logging is on at debug and
+    // info only.
+    Level slog.Level `yaml:"level"`
+}
+
+type Reminders struct {
+    Interval time.Duration `yaml:"interval"`
+    // Webhook is the URL reminders are posted to. If empty, they are written
+    // to the log.
+    Webhook  string        `yaml:"webhook"`
+}
+
+type Trash struct {
+    // Retention is how long deleted tasks stay in the trash before they are purged.
+    Retention     time.Duration `yaml:"retention"`
+    PurgeInterval time.Duration `yaml:"purge_interval"`
+}
+
+// Features switches optional parts of the server on and off.
+type Features struct {
+    // Swagger serves the API documentation under /docs.
+    Swagger    bool `yaml:"swagger"`
+    Reminders  bool `yaml:"reminders"`
+    TrashPurge bool `yaml:"trash_purge"`
+}
+
+// Default returns the configuration used for settings given in no source.
+func Default() Config {
+    return Config{
+        Server: Server{
+            Addr:              ":8080",
+            ReadHeaderTimeout: 5 * time.Second,
+            ReadTimeout:       15 * time.Second,
+            WriteTimeout:      30 * time.Second,
+            IdleTimeout:       60 * time.Second,
+            ShutdownTimeout:   15 * time.Second,
+        },
+        Database: Database{
+            QueryTimeout:    5 * time.Second,
+            MaxConns:        10,
+            MaxConnLifetime: time.Hour,
+            MaxConnIdleTime: 30 * time.Minute,
+        },
+        Log:       Log{Level: slog.LevelInfo},
+        Reminders: Reminders{Interval: time.Minute},
+        Trash:     Trash{Retention: 30 * 24 * time.Hour, PurgeInterval: time.Hour},
+        Features:  Features{Swagger: true, Reminders: true, TrashPurge: true},
+    }
+}
+
+// Validate reports every invalid setting at once, each prefixed with its key
+// in the configuration file.
+func (c *Config) Validate() error {
+    var errs []error
+    invalid := func(key, format string, args ...interface{}) {
+        errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+    }
+
+    if _, port, err := net.SplitHostPort(c.Server.Addr); err != nil || port == "" {
+        invalid("server.addr", "must be host:port or :port, got %q", c.Server.Addr)
+    }
+    for _, d := range []struct {
+        key   string
+        value time.Duration
+    }{
+        {"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+        {"server.read_timeout", c.Server.ReadTimeout},
+        {"server.write_timeout", c.Server.WriteTimeout},
+        {"server.idle_timeout", c.Server.IdleTimeout},
+        {"database.query_timeout", c.Database.QueryTimeout},
+        {"database.max_conn_lifetime", c.Database.MaxConnLifetime},
+        {"database.max_conn_idle_time", c.Database.MaxConnIdleTime},
+    } {
+        if d.value < 0 {
+            invalid(d.key, "must not be negative, got %s", d.value)
+        }
+    }
+    if c.Server.ShutdownTimeout <= 0 {
+        invalid("server.shutdown_timeout", "must be positive, got %s", c.Server.ShutdownTimeout)
+    }
+
+    // Without a storage or a URL, tasks are kept in memory, which has no
+    // connection pool.
+    sqlStorage := c.Database.URL != ""
+    switch c.Database.Storage {
+    case "":
+    case "memory":
+        sqlStorage = false
+    case "postgres", "sqlite":
+        if c.Database.URL == "" {
+            invalid("database.url", "is required for %s storage", c.Database.Storage)
+        }
+        sqlStorage = true
+    default:
+        invalid("database.storage", "must be postgres, sqlite or memory, got %q", c.Database.Storage)
+    }
+    if sqlStorage {
+        if c.Database.MaxConns < 1 {
+            invalid("database.max_conns", "must be at least 1, got %d", c.Database.MaxConns)
+        }
+        if c.Database.MinConns < 0 || c.Database.MinConns > c.Database.MaxConns {
+            invalid("database.min_conns", "must be between 0 and max_conns (%d), got %d", c.Database.MaxConns, c.Database.MinConns)
+        }
+    }
+
+    if c.Features.Reminders && c.Reminders.Interval <= 0 {
+        invalid("reminders.interval", "must be positive while reminders are enabled, got %s", c.Reminders.Interval)
+    }
+    if c.Reminders.Webhook != "" {
+        if u, err := url.Parse(c.Reminders.Webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+            invalid("reminders.webhook", "must be an http or https URL")
+        }
+    }
+
+    if c.Features.TrashPurge {
+        if c.Trash.Retention <= 0 {
+            invalid("trash.retention", "must be positive while trash purge is enabled, got %s", c.Trash.Retention)
+        }
+        if c.Trash.PurgeInterval <= 0 {
+            invalid("trash.purge_interval", "must be positive while trash purge is enabled, got %s", c.Trash.PurgeInterval)
+        }
+    }
+
+    return errors.Join(errs...)
+}
+
+// Redacted returns a copy of c with passwords in the database and webhook
+// URLs masked.
+func (c Config) Redacted() Config {
+    c.Database.URL = redact(c.Database.URL)
+    c.Reminders.Webhook = redact(c.Reminders.Webhook)
+    return c
+}
+
+// Dump writes c, redacted, as YAML in the format of the configuration file.
+func (c Config) Dump(w io.Writer) error {
+    enc := yaml.NewEncoder(w)
+    enc.SetIndent(2)
+    if err := enc.Encode(c.Redacted()); err != nil {
+        return err
+    }
+    return enc.Close()
+}
+
+const redactedPassword = "xxxxx"
+
+// dsnPasswordRe matches the password of a key=value PostgreSQL DSN.
+var dsnPasswordRe = regexp.MustCompile(`(password\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+
+func redact(s string) string {
+    if u, err := url.Parse(s); err == nil && u.Scheme != "" {
+        if _, ok := u.User.Password(); !ok {
+            return s
+        }
+        u.User = url.UserPassword(u.User.Username(), redactedPassword)
+        return u.String()
+    }
+    return dsnPasswordRe.ReplaceAllString(s, "${1}"+redactedPassword)
 }
@@ -0,0 +1,149 @@
+package config
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "sort"
+    "strings"
+
+    "gopkg.in/yaml.v3"
+)
+
+// FileEnv names the environment variable that may point to the configuration
+// file.
+const FileEnv = "TODO_CONFIG"
+
+// Load builds the configuration from the defaults, the YAML file at path, the
+// environment and the flag values in flags, keyed by flag name, in that order
+// of precedence, and validates it. An empty path falls back to TODO_CONFIG;
+// if that is unset too, no file is read.
+func Load(path string, flags map[string]string, lookupEnv func(string) (string, bool)) (*Config, error) {
+    if path == "" {
+        path, _ = lookupEnv(FileEnv)
+    }
+
+    cfg := Default()
+    if path != "" {
+        if err := loadFile(path, &cfg); err != nil {
+            return nil, err
+        }
+    }
+
+    fromEnv := func(name string) (string, bool) { return lookupEnv(EnvName(name)) }
+    if err := override(&cfg, fromEnv, EnvName); err != nil {
+        return nil, err
+    }
+
+    fromFlags := func(name string) (string, bool) {
+        value, ok := flags[name]
+        return value, ok
+    }
+    if err := override(&cfg, fromFlags, func(name string) string { return "--" + name }); err != nil {
+        return nil, err
+    }
+
+    if err := cfg.Validate(); err != nil {
+        return nil, fmt.Errorf("invalid configuration:\n%w", err)
+    }
+
+    return &cfg, nil
+}
+
+// Flags describes the command-line flag of every setting, with the defaults
+// of Default. Bool flags have a Value with an IsBoolFlag method, as in the
+// flag package.
+func Flags() []*flag.Flag {
+    cfg := Default()
+    fs := flag.NewFlagSet("config", flag.ContinueOnError)
+    bind(fs, &cfg)
+
+    var flags []*flag.Flag
+    fs.VisitAll(func(f *flag.Flag) { flags = append(flags, f) })
+    return flags
+}
+
+// bind defines a flag for every setting, writing into c and defaulting to its
+// current value.
+func bind(fs *flag.FlagSet, c *Config) {
+    fs.StringVar(&c.Server.Addr, "addr", c.Server.Addr, "address to listen on")
+    fs.DurationVar(&c.Server.ReadHeaderTimeout, "read-header-timeout", c.Server.ReadHeaderTimeout, "how long to wait for request headers")
+    fs.DurationVar(&c.Server.ReadTimeout, "read-timeout", c.Server.ReadTimeout, "how long to wait for a whole request (0 disables it)")
+    fs.DurationVar(&c.Server.WriteTimeout, "write-timeout", c.Server.WriteTimeout, "how long a response may take to write (0 disables it)")
+    fs.DurationVar(&c.Server.IdleTimeout, "idle-timeout", c.Server.IdleTimeout, "how long to keep idle connections open")
+    fs.DurationVar(&c.Server.ShutdownTimeout, "shutdown-timeout", c.Server.ShutdownTimeout, "how long to wait for in-flight requests to finish on SIGINT or SIGTERM")
+
+    fs.StringVar(&c.Database.Storage, "storage", c.Database.Storage, "task storage backend: postgres, sqlite or memory (default: inferred from the database URL)")
+    fs.StringVar(&c.Database.URL, "database-url", c.Database.URL, "PostgreSQL or sqlite:// database URL")
+    fs.DurationVar(&c.Database.QueryTimeout, "query-timeout", c.Database.QueryTimeout, "deadline for each storage operation (0 disables it)")
+    fs.IntVar(&c.Database.MaxConns, "db-max-conns", c.Database.MaxConns, "maximum size of the PostgreSQL connection pool")
+    fs.IntVar(&c.Database.MinConns, "db-min-conns", c.Database.MinConns, "number of PostgreSQL connections kept open when idle")
+    fs.DurationVar(&c.Database.MaxConnLifetime, "db-max-conn-lifetime", c.Database.MaxConnLifetime, "how long a PostgreSQL connection is reused before it is closed")
+    fs.DurationVar(&c.Database.MaxConnIdleTime, "db-max-conn-idle-time", c.Database.MaxConnIdleTime, "how long an idle PostgreSQL connection is kept")
+
+    fs.TextVar(&c.Log.Level, "log-level", c.Log.Level, "minimum level of log messages: debug, info, warn or error")
+
+    fs.BoolVar(&c.Features.Reminders, "reminders", c.Features.Reminders, "send reminders for tasks reaching their due time")
+    fs.DurationVar(&c.Reminders.Interval, "reminder-interval", c.Reminders.Interval, "how often to check for tasks reaching their due time")
+    fs.StringVar(&c.Reminders.Webhook, "reminder-webhook", c.Reminders.Webhook, "URL to POST due-task reminders to (default: write them to the log)")
+
+    fs.BoolVar(&c.Features.TrashPurge, "trash-purge", c.Features.TrashPurge, "purge expired tasks from the trash")
+    fs.DurationVar(&c.Trash.Retention, "trash-retention", c.Trash.Retention, "how long deleted tasks stay in the trash before they are purged")
+    fs.DurationVar(&c.Trash.PurgeInterval, "purge-interval", c.Trash.PurgeInterval, "how often to purge expired tasks from the trash")
+
+    fs.BoolVar(&c.Features.Swagger, "swagger", c.Features.Swagger, "serve the API documentation under /docs")
+
+    fs.VisitAll(func(f *flag.Flag) {
+        f.Usage += " (env " + EnvName(f.Name) + ")"
+    })
+}
+
+// EnvName returns the environment variable that sets the named flag.
+// The database URL keeps its conventional DATABASE_URL name.
+func EnvName(flagName string) string {
+    if flagName == "database-url" {
+        return "DATABASE_URL"
+    }
+    return "TODO_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// override sets every setting for which lookup returns a value, parsing it as
+// the setting's flag would. source names the origin of a value in errors.
+func override(c *Config, lookup func(name string) (string, bool), source func(name string) string) error {
+    fs := flag.NewFlagSet("override", flag.ContinueOnError)
+    bind(fs, c)
+
+    var names []string
+    fs.VisitAll(func(f *flag.Flag) { names = append(names, f.Name) })
+    sort.Strings(names)
+
+    for _, name := range names {
+        value, ok := lookup(name)
+        if !ok {
+            continue
+        }
+        if err := fs.Set(name, value); err != nil {
+            return fmt.Errorf("invalid %s: %w", source(name), err)
+        }
+    }
+    return nil
+}
+
+// loadFile overlays the settings present in the YAML file on c. Unknown keys
+// are rejected so that typos do not go unnoticed.
+func loadFile(path string, c *Config) error {
+    f, err := os.Open(path)
+    if err != nil {
+        return fmt.Errorf("failed to open config file: %w", err)
+    }
+    defer f.Close()
+
+    dec := yaml.NewDecoder(f)
+    dec.KnownFields(true)
+    if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+        return fmt.Errorf("failed to parse config file %s: %w", path, err)
+    }
+    return nil
+}
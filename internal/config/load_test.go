@@ -0,0 +1,134 @@
+package config
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestLoadPrecedence(t *testing.T) {
+    dir := t.TempDir()
+    file := filepath.Join(dir, "config.yaml")
+    yaml := `
+server:
+  addr: ":9000"
+  shutdown_timeout: 20s
+database:
+  url: postgres://file@db/todo
+  max_conns: 20
+features:
+  swagger: false
+`
+    if err := os.WriteFile(file, []byte(yaml), 0o600); err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        name  string
+        path  string
+        env   map[string]string
+        flags map[string]string
+        check func(c *Config) bool
+    }{
+        {
+            name:  "defaults",
+            check: func(c *Config) bool { return c.Server.Addr == ":8080" && c.Database.URL == "" && c.Features.Swagger },
+        },
+        {
+            name: "file over defaults",
+            path: file,
+            check: func(c *Config) bool {
+                return c.Server.Addr == ":9000" && c.Server.ShutdownTimeout == 20*time.Second &&
+                    c.Database.MaxConns == 20 && !c.Features.Swagger && c.Server.ReadTimeout == 15*time.Second
+            },
+        },
+        {
+            name:  "file from TODO_CONFIG",
+            env:   map[string]string{FileEnv: file},
+            check: func(c *Config) bool { return c.Server.Addr == ":9000" },
+        },
+        {
+            name:  "flag path over TODO_CONFIG",
+            path:  file,
+            env:   map[string]string{FileEnv: filepath.Join(dir, "missing.yaml")},
+            check: func(c *Config) bool { return c.Server.Addr == ":9000" },
+        },
+        {
+            name: "env over file",
+            path: file,
+            env:  map[string]string{"TODO_ADDR": ":9100", "DATABASE_URL": "postgres://env@db/todo", "TODO_SWAGGER": "true", "TODO_DB_MAX_CONNS": "30"},
+            check: func(c *Config) bool {
+                return c.Server.Addr == ":9100" && c.Database.URL == "postgres://env@db/todo" &&
+                    c.Features.Swagger && c.Database.MaxConns == 30 && c.Server.ShutdownTimeout == 20*time.Second
+            },
+        },
+        {
+            name:  "flags over env",
+            path:  file,
+            env:   map[string]string{"TODO_ADDR": ":9100", "DATABASE_URL": "postgres://env@db/todo"},
+            flags: map[string]string{"addr": ":9200", "swagger": "true"},
+            check: func(c *Config) bool {
+                return c.Server.Addr == ":9200" && c.Database.URL == "postgres://env@db/todo" && c.Features.Swagger
+            },
+        },
+        {
+            name:  "flag for the database URL",
+            env:   map[string]string{"DATABASE_URL": "postgres://env@db/todo"},
+            flags: map[string]string{"database-url": "sqlite:///tmp/todo.db"},
+            check: func(c *Config) bool { return c.Database.URL == "sqlite:///tmp/todo.db" },
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            lookupEnv := func(name string) (string, bool) {
+                value, ok := tt.env[name]
+                return value, ok
+            }
+            cfg, err := Load(tt.path, tt.flags, lookupEnv)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if !tt.check(cfg) {
+                t.Errorf("Load = %+v", *cfg)
+            }
+        })
+    }
+}
+
+func TestLoadErrors(t *testing.T) {
+    dir := t.TempDir()
+    unknown := filepath.Join(dir, "unknown.yaml")
+    if err := os.WriteFile(unknown, []byte("server:\n  adr: \":9000\"\n"), 0o600); err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        name  string
+        path  string
+        env   map[string]string
+        flags map[string]string
+        want  string
+    }{
+        {name: "missing file", path: filepath.Join(dir, "missing.yaml"), want: "failed to open config file"},
+        {name: "unknown key", path: unknown, want: "field adr not found"},
+        {name: "bad env value", env: map[string]string{"TODO_READ_TIMEOUT": "soon"}, want: "invalid TODO_READ_TIMEOUT"},
+        {name: "bad flag value", flags: map[string]string{"swagger": "maybe"}, want: "invalid --swagger"},
+        {name: "invalid result", flags: map[string]string{"shutdown-timeout": "0s"}, want: "server.shutdown_timeout"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            lookupEnv := func(name string) (string, bool) {
+                value, ok := tt.env[name]
+                return value, ok
+            }
+            _, err := Load(tt.path, tt.flags, lookupEnv)
+            if err == nil || !strings.Contains(err.Error(), tt.want) {
+                t.Errorf("Load error = %v, want %q", err, tt.want)
+            }
+        })
+    }
+}
@@ -0,0 +1,291 @@
+// Package filter implements the task filter language accepted by the q query
+// parameter, e.g. `done:false title~"deploy" (id>10 OR NOT done:true)`.
+//
+// Terms are written as field, operator and value. Adjacent terms are joined
+// with AND; OR, NOT (or a leading "-") and parentheses are also supported.
+package filter
+
+import (
+    "fmt"
+    "slices"
+    "strings"
+    "time"
+
+    "todo-golang/internal/model"
+)
+
+type Op string
+
+const (
+    OpEq       Op = ":"
+    OpNe       Op = "!="
+    OpContains Op = "~"
+    OpGt       Op = ">"
+    OpGe       Op = ">="
+    OpLt       Op = "<"
+    OpLe       Op = "<="
+)
+
+type Kind int
+
+const (
+    KindInt Kind = iota
+    KindString
+    KindBool
+    KindTime
+    // KindPriority values are written by name and compared as model.Priority levels.
+    KindPriority
+    // KindTags fields hold the task's tag names; ":" matches tasks carrying
+    // the tag and "!=" tasks without it.
+    KindTags
+)
+
+// Field is a filterable task attribute. Sortable fields may also be used in
+// sort keys. A nullable field's value is nil when the column is NULL; such a
+// value matches no condition and, for sortable timestamps, sorts as
+// NullSortValue.
+type Field struct {
+    Name     string
+    Column   string
+    Kind     Kind
+    Sortable bool
+    Nullable bool
+    value    func(model.Task) interface{}
+}
+
+// NullSortValue stands in for NULL timestamps when sorting, so that tasks
+// without the timestamp come after all others in ascending order.
+var NullSortValue = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+var (
+    createdField = &Field{Name: "created_at", Column: "created_at", Kind: KindTime, Sortable: true,
+        value: func(t model.Task) interface{} { return t.CreatedAt }}
+    updatedField = &Field{Name: "updated_at", Column: "updated_at", Kind: KindTime, Sortable: true,
+        value: func(t model.Task) interface{} { return t.UpdatedAt }}
+    dueField = &Field{Name: "due_at", Column: "due_at", Kind: KindTime, Sortable: true, Nullable: true,
+        value: func(t model.Task) interface{} { return optionalTime(t.DueAt) }}
+    projectField = &Field{Name: "project_id", Column: "project_id", Kind: KindInt, Nullable: true,
+        value: func(t model.Task) interface{} { return optionalInt(t.ProjectID) }}
+    parentField = &Field{Name: "parent_id", Column: "parent_id", Kind: KindInt, Nullable: true,
+        value: func(t model.Task) interface{} { return optionalInt(t.ParentID) }}
+    completedField = &Field{Name: "completed_at", Column: "completed_at", Kind: KindTime, Sortable: true, Nullable: true,
+        value: func(t model.Task) interface{} { return optionalTime(t.CompletedAt) }}
+    deletedField = &Field{Name: "deleted_at", Column: "deleted_at", Kind: KindTime, Sortable: true, Nullable: true,
+        value: func(t model.Task) interface{} { return optionalTime(t.DeletedAt) }}
+)
+
+var fields = map[string]*Field{
+    "id":           {Name: "id", Column: "id", Kind: KindInt, Sortable: true, value: func(t model.Task) interface{} { return t.ID }},
+    "title":        {Name: "title", Column: "title", Kind: KindString, Sortable: true, value: func(t model.Task) interface{} { return t.Title }},
+    "description":  {Name: "description", Column: "description", Kind: KindString, value: func(t model.Task) interface{} { return t.Description }},
+    // text searches the title and the description at once.
+    "text":         {Name: "text", Column: "(title || ' ' || description)", Kind: KindString, value: func(t model.Task) interface{} { return t.Title + " " + t.Description }},
+    // tag has no column: storage matches it against the task_tags table.
+    "tag":          {Name: "tag", Kind: KindTags, value: func(t model.Task) interface{} { return t.Tags }},
+    "project_id":   projectField,
+    "project":      projectField,
+    "parent_id":    parentField,
+    "parent":       parentField,
+    // blocked mirrors model.Task.Blocked, computed from task_dependencies.
+    "blocked":      {Name: "blocked", Column: "(tasks.done = FALSE AND EXISTS (SELECT 1 FROM task_dependencies d JOIN tasks b ON b.id = d.blocker_id WHERE d.task_id = tasks.id AND b.done = FALSE AND b.deleted_at IS NULL))", Kind: KindBool, value: func(t model.Task) interface{} { return t.Blocked }},
+    "done":         {Name: "done", Column: "done", Kind: KindBool, Sortable: true, value: func(t model.Task) interface{} { return t.Done }},
+    "priority":     {Name: "priority", Column: "priority", Kind: KindPriority, Sortable: true, value: func(t model.Task) interface{} { return int(t.Priority) }},
+    "due_at":       dueField,
+    "due":          dueField,
+    "created_at":   createdField,
+    "created":      createdField,
+    "updated_at":   updatedField,
+    "updated":      updatedField,
+    "completed_at": completedField,
+    "completed":    completedField,
+    "deleted_at":   deletedField,
+    "deleted":      deletedField,
+}
+
+func LookupField(name string) (*Field, bool) {
+    f, ok := fields[strings.ToLower(name)]
+    return f, ok
+}
+
+// Value returns the field's value for task.
+func (f *Field) Value(task model.Task) interface{} {
+    return f.value(task)
+}
+
+// SortValue returns the field's value for task as used for ordering, with
+// NULL replaced by NullSortValue.
+func (f *Field) SortValue(task model.Task) interface{} {
+    if v := f.value(task); v != nil {
+        return v
+    }
+    return NullSortValue
+}
+
+func optionalTime(t *time.Time) interface{} {
+    if t == nil {
+        return nil
+    }
+    return *t
+}
+
+func optionalInt(n *int) interface{} {
+    if n == nil {
+        return nil
+    }
+    return *n
+}
+
+func (f *Field) allows(op Op) bool {
+    switch f.Kind {
+    case KindBool, KindTags:
+        return op == OpEq || op == OpNe
+    case KindString:
+        return op == OpEq || op == OpNe || op == OpContains
+    default:
+        return op != OpContains
+    }
+}
+
+// Expr is a node of a parsed filter expression.
+type Expr interface {
+    expr()
+}
+
+type And struct{ Left, Right Expr }
+
+type Or struct{ Left, Right Expr }
+
+type Not struct{ X Expr }
+
+// Cond compares a field with a value already converted to the field's Go type:
+// int, string, bool or time.Time.
+type Cond struct {
+    Field *Field
+    Op    Op
+    Value interface{}
+}
+
+func (*And) expr()  {}
+func (*Or) expr()   {}
+func (*Not) expr()  {}
+func (*Cond) expr() {}
+
+// Equal returns a condition matching tasks whose field equals value. It panics
+// if the field is unknown, so it is meant for conditions built in code.
+func Equal(field string, value interface{}) Expr {
+    return NewCond(field, OpEq, value)
+}
+
+// NewCond returns a condition comparing field with value. Like Equal, it
+// panics if the field is unknown.
+func NewCond(field string, op Op, value interface{}) Expr {
+    f, ok := LookupField(field)
+    if !ok {
+        panic(fmt.Sprintf("filter: unknown field %q", field))
+    }
+    return &Cond{Field: f, Op: op, Value: value}
+}
+
+// AllOf joins the non-nil expressions with AND. It returns nil if there are none.
+func AllOf(exprs ...Expr) Expr {
+    var result Expr
+    for _, e := range exprs {
+        switch {
+        case e == nil:
+        case result == nil:
+            result = e
+        default:
+            result = &And{Left: result, Right: e}
+        }
+    }
+    return result
+}
+
+// AnyOf joins the non-nil expressions with OR. It returns nil if there are none.
+func AnyOf(exprs ...Expr) Expr {
+    var result Expr
+    for _, e := range exprs {
+        switch {
+        case e == nil:
+        case result == nil:
+            result = e
+        default:
+            result = &Or{Left: result, Right: e}
+        }
+    }
+    return result
+}
+
+// Match reports whether task satisfies e. A nil expression matches every task.
+func Match(e Expr, task model.Task) bool {
+    switch e := e.(type) {
+    case nil:
+        return true
+    case *And:
+        return Match(e.Left, task) && Match(e.Right, task)
+    case *Or:
+        return Match(e.Left, task) || Match(e.Right, task)
+    case *Not:
+        return !Match(e.X, task)
+    case *Cond:
+        return e.match(task)
+    default:
+        panic(fmt.Sprintf("filter: unexpected expression %T", e))
+    }
+}
+
+func (c *Cond) match(task model.Task) bool {
+    actual := c.Field.value(task)
+    if actual == nil {
+        return false
+    }
+
+    if c.Field.Kind == KindTags {
+        has := slices.Contains(actual.([]string), c.Value.(string))
+        return has == (c.Op == OpEq)
+    }
+
+    if c.Op == OpContains {
+        return strings.Contains(strings.ToLower(actual.(string)), strings.ToLower(c.Value.(string)))
+    }
+
+    cmp := compare(actual, c.Value)
+    switch c.Op {
+    case OpEq:
+        return cmp == 0
+    case OpNe:
+        return cmp != 0
+    case OpGt:
+        return cmp > 0
+    case OpGe:
+        return cmp >= 0
+    case OpLt:
+        return cmp < 0
+    case OpLe:
+        return cmp <= 0
+    default:
+        return false
+    }
+}
+
+func compare(a, b interface{}) int {
+    switch a := a.(type) {
+    case int:
+        return a - b.(int)
+    case string:
+        return strings.Compare(a, b.(string))
+    case bool:
+        switch {
+        case a == b.(bool):
+            return 0
+        case a:
+            return 1
+        default:
+            return -1
+        }
+    case time.Time:
+        return a.Compare(b.(time.Time))
+    default:
+        panic(fmt.Sprintf("filter: unexpected value %T", a))
+    }
+}
@@ -0,0 +1,311 @@
+package filter
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+    "unicode"
+
+    "todo-golang/internal/model"
+)
+
+// SyntaxError describes an invalid filter expression. Pos is the 1-based
+// byte offset in the input where the problem was found.
+type SyntaxError struct {
+    Pos int
+    Msg string
+}
+
+func (e *SyntaxError) Error() string {
+    return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+// Parse parses a filter expression. An empty or blank input yields a nil
+// expression, which matches every task.
+func Parse(input string) (Expr, error) {
+    return ParseIn(input, time.UTC)
+}
+
+// ParseIn is like Parse but takes plain dates, which carry no offset, as
+// midnight in loc.
+func ParseIn(input string, loc *time.Location) (Expr, error) {
+    p := &parser{input: input, loc: loc}
+
+    p.skipSpace()
+    if p.eof() {
+        return nil, nil
+    }
+
+    e, err := p.parseOr()
+    if err != nil {
+        return nil, err
+    }
+
+    p.skipSpace()
+    if !p.eof() {
+        return nil, p.errorf(p.pos, "unexpected %q", string(p.input[p.pos]))
+    }
+
+    return e, nil
+}
+
+type parser struct {
+    input string
+    pos   int
+    loc   *time.Location
+}
+
+func (p *parser) errorf(pos int, format string, args ...interface{}) error {
+    return &SyntaxError{Pos: pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) eof() bool {
+    return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() byte {
+    if p.eof() {
+        return 0
+    }
+    return p.input[p.pos]
+}
+
+func (p *parser) skipSpace() {
+    for !p.eof() && unicode.IsSpace(rune(p.input[p.pos])) {
+        p.pos++
+    }
+}
+
+// keyword consumes the case-insensitive word kw if it comes next as a whole word.
+func (p *parser) keyword(kw string) bool {
+    end := p.pos + len(kw)
+    if end > len(p.input) || !strings.EqualFold(p.input[p.pos:end], kw) {
+        return false
+    }
+    if end < len(p.input) && !unicode.IsSpace(rune(p.input[end])) && p.input[end] != '(' {
+        return false
+    }
+
+    p.pos = end
+    return true
+}
+
+func (p *parser) parseOr() (Expr, error) {
+    left, err := p.parseAnd()
+    if err != nil {
+        return nil, err
+    }
+
+    for {
+        p.skipSpace()
+        if !p.keyword("OR") {
+            return left, nil
+        }
+
+        right, err := p.parseAnd()
+        if err != nil {
+            return nil, err
+        }
+        left = &Or{Left: left, Right: right}
+    }
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+    left, err := p.parseUnary()
+    if err != nil {
+        return nil, err
+    }
+
+    for {
+        p.skipSpace()
+        if p.eof() || p.peek() == ')' {
+            return left, nil
+        }
+
+        start := p.pos
+        if p.keyword("OR") {
+            p.pos = start
+            return left, nil
+        }
+        p.keyword("AND")
+
+        right, err := p.parseUnary()
+        if err != nil {
+            return nil, err
+        }
+        left = &And{Left: left, Right: right}
+    }
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+    p.skipSpace()
+
+    if p.peek() == '-' {
+        p.pos++
+    } else if !p.keyword("NOT") {
+        return p.parsePrimary()
+    }
+
+    x, err := p.parseUnary()
+    if err != nil {
+        return nil, err
+    }
+    return &Not{X: x}, nil
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+    p.skipSpace()
+
+    if p.eof() {
+        return nil, p.errorf(p.pos, "unexpected end of expression")
+    }
+
+    if p.peek() != '(' {
+        return p.parseCond()
+    }
+
+    open := p.pos
+    p.pos++
+    e, err := p.parseOr()
+    if err != nil {
+        return nil, err
+    }
+
+    p.skipSpace()
+    if p.peek() != ')' {
+        return nil, p.errorf(open, "unclosed parenthesis")
+    }
+    p.pos++
+
+    return e, nil
+}
+
+func (p *parser) parseCond() (Expr, error) {
+    start := p.pos
+    for !p.eof() && (p.peek() == '_' || unicode.IsLetter(rune(p.peek()))) {
+        p.pos++
+    }
+
+    name := p.input[start:p.pos]
+    if name == "" {
+        return nil, p.errorf(start, "expected field name")
+    }
+
+    field, ok := LookupField(name)
+    if !ok {
+        return nil, p.errorf(start, "unknown field %q", name)
+    }
+
+    opPos := p.pos
+    op, ok := p.parseOp()
+    if !ok {
+        return nil, p.errorf(opPos, "expected operator after %q", name)
+    }
+    if !field.allows(op) {
+        return nil, p.errorf(opPos, "operator %q is not supported for field %q", op, field.Name)
+    }
+
+    valuePos := p.pos
+    raw, err := p.parseValue()
+    if err != nil {
+        return nil, err
+    }
+
+    value, err := convertValue(field.Kind, raw, p.loc)
+    if err != nil {
+        return nil, p.errorf(valuePos, "invalid value %q for field %q: %v", raw, field.Name, err)
+    }
+
+    return &Cond{Field: field, Op: op, Value: value}, nil
+}
+
+func (p *parser) parseOp() (Op, bool) {
+    for _, op := range []Op{OpGe, OpLe, OpNe, OpEq, OpContains, OpGt, OpLt, "="} {
+        if strings.HasPrefix(p.input[p.pos:], string(op)) {
+            p.pos += len(op)
+            if op == "=" {
+                return OpEq, true
+            }
+            return op, true
+        }
+    }
+    return "", false
+}
+
+func (p *parser) parseValue() (string, error) {
+    start := p.pos
+
+    if p.peek() != '"' {
+        for !p.eof() && !unicode.IsSpace(rune(p.peek())) && p.peek() != ')' {
+            p.pos++
+        }
+        if p.pos == start {
+            return "", p.errorf(start, "expected value")
+        }
+        return p.input[start:p.pos], nil
+    }
+
+    var b strings.Builder
+    p.pos++
+    for {
+        if p.eof() {
+            return "", p.errorf(start, "unterminated string")
+        }
+
+        c := p.input[p.pos]
+        p.pos++
+        switch {
+        case c == '"':
+            return b.String(), nil
+        case c == '\\' && !p.eof():
+            b.WriteByte(p.input[p.pos])
+            p.pos++
+        default:
+            b.WriteByte(c)
+        }
+    }
+}
+
+func convertValue(kind Kind, raw string, loc *time.Location) (interface{}, error) {
+    switch kind {
+    case KindInt:
+        n, err := strconv.Atoi(raw)
+        if err != nil {
+            return nil, fmt.Errorf("expected an integer")
+        }
+        return n, nil
+    case KindBool:
+        b, err := strconv.ParseBool(raw)
+        if err != nil {
+            return nil, fmt.Errorf("expected true or false")
+        }
+        return b, nil
+    case KindTime:
+        return ParseTime(raw, loc)
+    case KindTags:
+        return model.NormalizeTag(raw)
+    case KindPriority:
+        p, err := model.ParsePriority(raw)
+        if err != nil {
+            return nil, fmt.Errorf("expected low, normal, high or urgent")
+        }
+        return int(p), nil
+    default:
+        return raw, nil
+    }
+}
+
+// ParseTime accepts RFC 3339 timestamps and plain dates, which are taken as
+// midnight in loc. The result is always in UTC, like stored timestamps.
+func ParseTime(raw string, loc *time.Location) (time.Time, error) {
+    if t, err := time.Parse(time.RFC3339, raw); err == nil {
+        return t.UTC(), nil
+    }
+
+    t, err := time.ParseInLocation("2006-01-02", raw, loc)
+    if err != nil {
+        return time.Time{}, fmt.Errorf("expected a date (2006-01-02) or RFC 3339 timestamp")
+    }
+    return t.UTC(), nil
+}
@@ -0,0 +1,216 @@
+package filter
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+    "testing"
+    "time"
+
+    "todo-golang/internal/model"
+)
+
+// format prints an expression with explicit parentheses around every AND and
+// OR, so that tests can state the tree the parser built.
+func format(e Expr) string {
+    switch e := e.(type) {
+    case nil:
+        return "<nil>"
+    case *And:
+        return "(" + format(e.Left) + " AND " + format(e.Right) + ")"
+    case *Or:
+        return "(" + format(e.Left) + " OR " + format(e.Right) + ")"
+    case *Not:
+        return "NOT " + format(e.X)
+    case *Cond:
+        switch v := e.Value.(type) {
+        case string:
+            return fmt.Sprintf("%s%s%q", e.Field.Name, e.Op, v)
+        case time.Time:
+            return fmt.Sprintf("%s%s%s", e.Field.Name, e.Op, v.Format(time.RFC3339))
+        default:
+            return fmt.Sprintf("%s%s%v", e.Field.Name, e.Op, v)
+        }
+    default:
+        return fmt.Sprintf("%T", e)
+    }
+}
+
+func TestParse(t *testing.T) {
+    tests := []struct {
+        input string
+        want  string
+    }{
+        {``, `<nil>`},
+        {`   `, `<nil>`},
+        {`done:false`, `done:false`},
+        {`DONE=true`, `done:true`},
+        {`priority>=high`, `priority>=3`},
+        {`due<2030-01-01`, `due_at<2030-01-01T00:00:00Z`},
+        {`completed>2030-01-01T12:00:00+03:00`, `completed_at>2030-01-01T09:00:00Z`},
+        {`tag:Work`, `tag:"work"`},
+        {`tag!=urgent`, `tag!="urgent"`},
+
+        // AND binds tighter than OR, whether it is written or implied.
+        {`done:true priority:high OR id>10`, `((done:true AND priority:3) OR id>10)`},
+        {`id>1 OR id<5 id!=3`, `(id>1 OR (id<5 AND id!=3))`},
+        {`id>1 AND id<5 OR done:true`, `((id>1 AND id<5) OR done:true)`},
+        {`id>1 and id<5 or done:true`, `((id>1 AND id<5) OR done:true)`},
+        {`id:1 OR id:2 OR id:3`, `((id:1 OR id:2) OR id:3)`},
+        {`id>1 AND (id<5 OR done:true)`, `(id>1 AND (id<5 OR done:true))`},
+        {`((id:1))`, `id:1`},
+
+        // NOT and "-" apply to the next term only.
+        {`-done:true`, `NOT done:true`},
+        {`NOT done:true id:1`, `(NOT done:true AND id:1)`},
+        {`not done:true or id:1`, `(NOT done:true OR id:1)`},
+        {`NOT(done:true)`, `NOT done:true`},
+        {`NOT NOT done:true`, `NOT NOT done:true`},
+        {`--done:true`, `NOT NOT done:true`},
+        {`-(id>1 OR id<0) title~x`, `(NOT (id>1 OR id<0) AND title~"x")`},
+        {`-due<2030-01-01`, `NOT due_at<2030-01-01T00:00:00Z`},
+
+        // Quoted values keep spaces, parentheses and keywords; a backslash
+        // escapes the next character.
+        {`title:"Buy milk"`, `title:"Buy milk"`},
+        {`title:"(x) OR y"`, `title:"(x) OR y"`},
+        {`title:"say \"hi\""`, `title:"say \"hi\""`},
+        {`title~"a\\b"`, `title~"a\\b"`},
+        {`title:"\x"`, `title:"x"`},
+        {`title:""`, `title:""`},
+        {`text~"Отчет за Q1"`, `text~"Отчет за Q1"`},
+        {`(title:x)`, `title:"x"`},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.input, func(t *testing.T) {
+            e, err := Parse(tt.input)
+            if err != nil {
+                t.Fatalf("Parse(%q): %v", tt.input, err)
+            }
+            if got := format(e); got != tt.want {
+                t.Errorf("Parse(%q) = %s, want %s", tt.input, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestParseErrors(t *testing.T) {
+    tests := []struct {
+        input string
+        pos   int
+        msg   string
+    }{
+        {`(done:true`, 1, `unclosed parenthesis`},
+        {`id>1 AND (done:true OR (id<5)`, 10, `unclosed parenthesis`},
+        {`done:true)`, 10, `unexpected ")"`},
+        {`title:x) id:1`, 8, `unexpected ")"`},
+        {`done:true OR`, 13, `unexpected end of expression`},
+        {`NOT`, 4, `unexpected end of expression`},
+        {`-`, 2, `unexpected end of expression`},
+        {`()`, 2, `expected field name`},
+        {`:true`, 1, `expected field name`},
+        {`owner:me`, 1, `unknown field "owner"`},
+        {`done:true assignee:x`, 11, `unknown field "assignee"`},
+        {`done`, 5, `expected operator after "done"`},
+        {`done true`, 5, `expected operator after "done"`},
+
+        // Operators a field does not allow are reported at the operator.
+        {`done~true`, 5, `operator "~" is not supported for field "done"`},
+        {`done>false`, 5, `operator ">" is not supported for field "done"`},
+        {`tag>=x`, 4, `operator ">=" is not supported for field "tag"`},
+        {`id~5`, 3, `operator "~" is not supported for field "id"`},
+        {`title<abc`, 6, `operator "<" is not supported for field "title"`},
+        {`due~2030`, 4, `operator "~" is not supported for field "due_at"`},
+
+        // Bad values are reported at the start of the value.
+        {`id:`, 4, `expected value`},
+        {`id: 5`, 4, `expected value`},
+        {`title:"abc`, 7, `unterminated string`},
+        {`title:"abc\"`, 7, `unterminated string`},
+        {`id:abc`, 4, `invalid value "abc" for field "id"`},
+        {`done:yes`, 6, `invalid value "yes" for field "done"`},
+        {`priority:extreme`, 10, `invalid value "extreme" for field "priority"`},
+        {`due<tomorrow`, 5, `invalid value "tomorrow" for field "due_at"`},
+        {`tag:"two words"`, 5, `invalid value "two words" for field "tag"`},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.input, func(t *testing.T) {
+            e, err := Parse(tt.input)
+            var syntaxErr *SyntaxError
+            if !errors.As(err, &syntaxErr) {
+                t.Fatalf("Parse(%q) = %s, %v; want a syntax error", tt.input, format(e), err)
+            }
+            if syntaxErr.Pos != tt.pos || !strings.Contains(syntaxErr.Msg, tt.msg) {
+                t.Errorf("Parse(%q) error = %v, want %q at position %d", tt.input, err, tt.msg, tt.pos)
+            }
+        })
+    }
+}
+
+func TestParseIn(t *testing.T) {
+    msk := time.FixedZone("MSK", 3*60*60)
+
+    tests := []struct {
+        input string
+        loc   *time.Location
+        want  string
+    }{
+        {`due<2030-01-01`, time.UTC, `due_at<2030-01-01T00:00:00Z`},
+        {`due<2030-01-01`, msk, `due_at<2029-12-31T21:00:00Z`},
+        // Timestamps carry their own offset and ignore loc.
+        {`due<2030-01-01T00:00:00Z`, msk, `due_at<2030-01-01T00:00:00Z`},
+        {`due<2030-01-01T00:00:00+03:00`, time.UTC, `due_at<2029-12-31T21:00:00Z`},
+    }
+
+    for _, tt := range tests {
+        e, err := ParseIn(tt.input, tt.loc)
+        if err != nil {
+            t.Fatalf("ParseIn(%q, %s): %v", tt.input, tt.loc, err)
+        }
+        if got := format(e); got != tt.want {
+            t.Errorf("ParseIn(%q, %s) = %s, want %s", tt.input, tt.loc, got, tt.want)
+        }
+    }
+}
+
+func TestMatchNullable(t *testing.T) {
+    due := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+    project := 1
+    withDue := model.Task{ID: 1, Title: "Deploy", DueAt: &due, ProjectID: &project}
+    without := model.Task{ID: 2, Title: "Docs"}
+
+    tests := []struct {
+        q       string
+        withDue bool
+        without bool
+    }{
+        {`due<2031-01-01`, true, false},
+        {`due>=2031-01-01`, false, false},
+        {`due!=2031-01-01`, true, false},
+        // A missing value matches no condition, so its negation matches.
+        {`-due<2031-01-01`, false, true},
+        {`-due>=2031-01-01`, true, true},
+        {`NOT due!=2031-01-01`, false, true},
+        {`project:1`, true, false},
+        {`project!=1`, false, false},
+        {`-project:1`, false, true},
+        {`-project!=1`, true, true},
+        {`completed>2000-01-01 OR title:Docs`, false, true},
+        {`-(due<2031-01-01 OR project:2)`, false, true},
+    }
+
+    for _, tt := range tests {
+        e, err := Parse(tt.q)
+        if err != nil {
+            t.Fatalf("Parse(%q): %v", tt.q, err)
+        }
+        if got := Match(e, withDue); got != tt.withDue {
+            t.Errorf("Match(%q) on a task with the field = %v, want %v", tt.q, got, tt.withDue)
+        }
+        if got := Match(e, without); got != tt.without {
+            t.Errorf("Match(%q) on a task without the field = %v, want %v", tt.q, got, tt.without)
+        }
+    }
+}
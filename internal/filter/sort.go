@@ -0,0 +1,98 @@
+package filter
+
+import (
+    "fmt"
+    "strings"
+
+    "todo-golang/internal/model"
+)
+
+// SortKey orders tasks by one field, ascending unless Desc is set.
+type SortKey struct {
+    Field *Field
+    Desc  bool
+}
+
+// ParseSort parses a comma-separated list of sortable fields, each optionally
+// prefixed with "-" for descending order, e.g. "done,-id".
+func ParseSort(spec string) ([]SortKey, error) {
+    if strings.TrimSpace(spec) == "" {
+        return nil, nil
+    }
+
+    var keys []SortKey
+    seen := make(map[string]bool)
+
+    for _, part := range strings.Split(spec, ",") {
+        part = strings.TrimSpace(part)
+
+        var key SortKey
+        if strings.HasPrefix(part, "-") {
+            key.Desc = true
+            part = part[1:]
+        } else {
+            part = strings.TrimPrefix(part, "+")
+        }
+
+        field, ok := LookupField(part)
+        if !ok || !field.Sortable {
+            return nil, fmt.Errorf("cannot sort by %q", part)
+        }
+        if seen[field.Name] {
+            return nil, fmt.Errorf("field %q is listed more than once", field.Name)
+        }
+        seen[field.Name] = true
+
+        key.Field = field
+        keys = append(keys, key)
+    }
+
+    return keys, nil
+}
+
+// MustParseSort is like ParseSort but panics on error. It is meant for sort
+// orders fixed in code.
+func MustParseSort(spec string) []SortKey {
+    keys, err := ParseSort(spec)
+    if err != nil {
+        panic(fmt.Sprintf("filter: %v", err))
+    }
+    return keys
+}
+
+// FormatSort is the inverse of ParseSort.
+func FormatSort(keys []SortKey) string {
+    parts := make([]string, len(keys))
+    for i, key := range keys {
+        parts[i] = key.Field.Name
+        if key.Desc {
+            parts[i] = "-" + parts[i]
+        }
+    }
+    return strings.Join(parts, ",")
+}
+
+// SortValues returns the task's values for each key, as stored in pagination cursors.
+func SortValues(task model.Task, keys []SortKey) []interface{} {
+    values := make([]interface{}, len(keys))
+    for i, key := range keys {
+        values[i] = key.Field.SortValue(task)
+    }
+    return values
+}
+
+// CompareSortValues compares the task's position in the order given by keys
+// with the position described by values. The result is negative if the task
+// comes first, zero if the positions are equal and positive otherwise.
+func CompareSortValues(task model.Task, keys []SortKey, values []interface{}) int {
+    for i, key := range keys {
+        cmp := compare(key.Field.SortValue(task), values[i])
+        if key.Desc {
+            cmp = -cmp
+        }
+        if cmp != 0 {
+            return cmp
+        }
+    }
+    return 0
+}
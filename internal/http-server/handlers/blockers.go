@@ -0,0 +1,102 @@
+package handlers
+
+import (
+    "encoding/json"
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/go-chi/chi/v5"
+
+    "todo-golang/storage"
+)
+
+// AddTaskBlocker
+// @Summary Добавить блокирующую задачу
+// @Description Отмечает, что задачу нельзя выполнить, пока не выполнена блокирующая задача. Зависимость, образующая цикл, отклоняется
+// @Tags tasks
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param blockerID path int true "ID блокирующей задачи"
+// @Success 200 {object} model.Task "Задача с обновленным списком блокирующих задач"
+// @Failure 400 {object} map[string]string "Некорректный идентификатор задачи"
+// @Failure 404 {object} map[string]string "Задача не найдена"
+// @Failure 409 {object} map[string]string "Зависимость образует цикл"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /tasks/{id}/blockers/{blockerID} [post]
+func (h *TaskHandler) AddTaskBlocker(w http.ResponseWriter, r *http.Request) {
+    id, blockerID, ok := parseTaskBlocker(w, r)
+    if !ok {
+        return
+    }
+
+    if err := h.repo.AddBlocker(r.Context(), id, blockerID); err != nil {
+        switch {
+        case errors.Is(err, storage.ErrNotFound):
+            http.Error(w, errorMessage(err), http.StatusNotFound)
+        case errors.Is(err, storage.ErrValidation):
+            http.Error(w, errorMessage(err), http.StatusBadRequest)
+        case errors.Is(err, storage.ErrConflict):
+            http.Error(w, errorMessage(err), http.StatusConflict)
+        default:
+            http.Error(w, "Failed to add blocker", http.StatusInternalServerError)
+        }
+        return
+    }
+
+    task, err := h.repo.GetByID(r.Context(), id)
+    if err != nil {
+        http.Error(w, "Failed to fetch task", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(task)
+}
+
+// RemoveTaskBlocker
+// @Summary Удалить блокирующую задачу
+// @Description Удаляет зависимость задачи от блокирующей задачи
+// @Tags tasks
+// @Param id path int true "ID задачи"
+// @Param blockerID path int true "ID блокирующей задачи"
+// @Success 204 "Зависимость удалена"
+// @Failure 400 {object} map[string]string "Некорректный идентификатор задачи"
+// @Failure 404 {object} map[string]string "Задача не найдена"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /tasks/{id}/blockers/{blockerID} [delete]
+func (h *TaskHandler) RemoveTaskBlocker(w http.ResponseWriter, r *http.Request) {
+    id, blockerID, ok := parseTaskBlocker(w, r)
+    if !ok {
+        return
+    }
+
+    if err := h.repo.RemoveBlocker(r.Context(), id, blockerID); err != nil {
+        if errors.Is(err, storage.ErrNotFound) {
+            http.Error(w, "Task not found", http.StatusNotFound)
+            return
+        }
+        http.Error(w, "Failed to remove blocker", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// parseTaskBlocker reads the task and blocker IDs from the URL. It writes the
+// error response and returns false if either is invalid.
+func parseTaskBlocker(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+    id, err := strconv.Atoi(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid task ID", http.StatusBadRequest)
+        return 0, 0, false
+    }
+
+    blockerID, err := strconv.Atoi(chi.URLParam(r, "blockerID"))
+    if err != nil {
+        http.Error(w, "Invalid blocker task ID", http.StatusBadRequest)
+        return 0, 0, false
+    }
+
+    return id, blockerID, true
+}
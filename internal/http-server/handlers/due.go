@@ -0,0 +1,65 @@
+package handlers
+
+import (
+    "net/http"
+    "time"
+
+    "todo-golang/internal/filter"
+    "todo-golang/internal/model"
+    "todo-golang/storage"
+)
+
+const defaultDueWithin = 24 * time.Hour
+
+// dueSort lists the nearest deadlines first.
+var dueSort = filter.MustParseSort("due_at")
+
+// GetDueTasks
+// @Summary Получить задачи с приближающимся сроком
+// @Description Возвращает невыполненные задачи, срок которых наступает в течение интервала within, начиная с ближайших
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param within query string false "Интервал от текущего момента, например 48h или 90m (по умолчанию 24h)"
+// @Param q query string false "Дополнительное выражение фильтра, например: title~\"deploy\""
+// @Param tags_all query string false "Теги через запятую: только задачи со всеми тегами"
+// @Param tags_any query string false "Теги через запятую: задачи хотя бы с одним из тегов"
+// @Param tz query string false "Часовой пояс IANA для дат без времени в q, например Europe/Moscow (по умолчанию UTC)"
+// @Param sort query string false "Сортировка (по умолчанию due_at)"
+// @Param limit query int false "Размер страницы (1-500, по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param render query string false "Формат описаний: markdown (по умолчанию) или html"
+// @Success 200 {object} TaskPage "Страница списка задач"
+// @Header 200 {string} Link "Ссылка на следующую страницу (rel=next)"
+// @Failure 400 {object} map[string]string "Некорректные параметры запроса"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /tasks/due [get]
+func (h *TaskHandler) GetDueTasks(w http.ResponseWriter, r *http.Request) {
+    within := defaultDueWithin
+    if withinStr := r.URL.Query().Get("within"); withinStr != "" {
+        d, err := time.ParseDuration(withinStr)
+        if err != nil || d <= 0 {
+            http.Error(w, "Invalid 'within' query parameter: expected a positive duration such as 48h", http.StatusBadRequest)
+            return
+        }
+        within = d
+    }
+
+    expr, err := parseFilterQuery(r)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    now := time.Now().UTC()
+    expr = filter.AllOf(
+        filter.Equal("done", false),
+        filter.NewCond("due_at", filter.OpGe, now),
+        filter.NewCond("due_at", filter.OpLe, now.Add(within)),
+        expr,
+    )
+
+    h.listTasks(w, r, dueSort, func(page storage.Page) ([]model.Task, error) {
+        return h.repo.GetFiltered(r.Context(), expr, page)
+    })
+}
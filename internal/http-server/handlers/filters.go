@@ -1,10 +1,13 @@
 package handlers
 
 import (
-    "encoding/json"
+    "fmt"
     "net/http"
 	"strconv"
+    "time"
 
+    "todo-golang/internal/filter"
+    "todo-golang/internal/model"
     "todo-golang/storage"
 )
 
@@ -22,13 +25,24 @@ func NewTaskHandler(repo storage.TaskRepository) *TaskHandler {
 // @Tags tasks
 // @Accept json
 // @Produce json
-// @Param done query bool true "Статус выполнения (true - выполненные, false - не выполненные)"
-// @Success 200 {array} model.Task "Список задач"
+// @Param done query bool false "Статус выполнения (true - выполненные, false - не выполненные)"
+// @Param q query string false "Дополнительное выражение фильтра, например: title~\"deploy\""
+// @Param overdue query bool false "true - только просроченные невыполненные задачи, false - все остальные"
+// @Param tags_all query string false "Теги через запятую: только задачи со всеми тегами"
+// @Param tags_any query string false "Теги через запятую: задачи хотя бы с одним из тегов"
+// @Param tz query string false "Часовой пояс IANA для дат без времени в q, например Europe/Moscow (по умолчанию UTC)"
+// @Param sort query string false "Сортировка: поля через запятую, '-' - по убыванию (id, title, done, priority, due_at, created_at, updated_at, completed_at)"
+// @Param limit query int false "Размер страницы (1-500, по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param render query string false "Формат описаний: markdown (по умолчанию) или html"
+// @Success 200 {object} TaskPage "Страница списка задач"
+// @Header 200 {string} Link "Ссылка на следующую страницу (rel=next)"
+// @Failure 400 {object} map[string]string "Некорректные параметры запроса"
 // @Failure 500 {object} map[string]string "Ошибка сервера"
-// @Router /tasks/filtered [get]
+// @Router /tasks/filter [get]
 func (h *TaskHandler) GetFilteredTasks(w http.ResponseWriter, r *http.Request) {
     doneStr := r.URL.Query().Get("done")
-    var doneFilter *bool
+    var doneFilter filter.Expr
 
     if doneStr != "" {
         done, err := strconv.ParseBool(doneStr)
@@ -36,15 +50,84 @@ func (h *TaskHandler) GetFilteredTasks(w http.ResponseWriter, r *http.Request) {
             http.Error(w, "Invalid 'done' query parameter", http.StatusBadRequest)
             return
         }
-        doneFilter = &done
+        doneFilter = filter.Equal("done", done)
     }
 
-    tasks, err := h.repo.GetFiltered(doneFilter)
+    expr, err := parseFilterQuery(r)
     if err != nil {
-        http.Error(w, "Failed to fetch tasks", http.StatusInternalServerError)
+        http.Error(w, err.Error(), http.StatusBadRequest)
         return
     }
 
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(tasks)
+    expr = filter.AllOf(doneFilter, expr)
+    h.listTasks(w, r, nil, func(page storage.Page) ([]model.Task, error) {
+        return h.repo.GetFiltered(r.Context(), expr, page)
+    })
+}
+
+// parseFilterQuery builds a listing filter from the q, overdue, tags_all and
+// tags_any query parameters, limited to the request's project if it has one.
+// Plain dates in q are read in the time zone given by tz. Syntax errors carry
+// the position of the offending input.
+func parseFilterQuery(r *http.Request) (filter.Expr, error) {
+    loc, err := parseLocation(r)
+    if err != nil {
+        return nil, err
+    }
+
+    expr, err := filter.ParseIn(r.URL.Query().Get("q"), loc)
+    if err != nil {
+        return nil, fmt.Errorf("Invalid 'q' query parameter: %w", err)
+    }
+
+    if overdueStr := r.URL.Query().Get("overdue"); overdueStr != "" {
+        overdue, err := strconv.ParseBool(overdueStr)
+        if err != nil {
+            return nil, fmt.Errorf("Invalid 'overdue' query parameter")
+        }
+
+        cond := overdueFilter(time.Now())
+        if !overdue {
+            cond = &filter.Not{X: cond}
+        }
+        expr = filter.AllOf(expr, cond)
+    }
+
+    for _, param := range []string{"tags_all", "tags_any"} {
+        list := r.URL.Query().Get(param)
+        if list == "" {
+            continue
+        }
+
+        cond, err := parseTagFilter(list, param == "tags_any")
+        if err != nil {
+            return nil, fmt.Errorf("Invalid '%s' query parameter: %v", param, err)
+        }
+        expr = filter.AllOf(expr, cond)
+    }
+
+    if projectID, ok := projectFromContext(r.Context()); ok {
+        expr = filter.AllOf(expr, filter.Equal("project_id", projectID))
+    }
+
+    return expr, nil
+}
+
+// overdueFilter matches open tasks whose due time is before now.
+func overdueFilter(now time.Time) filter.Expr {
+    return filter.AllOf(filter.Equal("done", false), filter.NewCond("due_at", filter.OpLt, now.UTC()))
+}
+
+// parseLocation returns the time zone named by the tz query parameter, UTC if it is absent.
+func parseLocation(r *http.Request) (*time.Location, error) {
+    name := r.URL.Query().Get("tz")
+    if name == "" {
+        return time.UTC, nil
+    }
+
+    loc, err := time.LoadLocation(name)
+    if err != nil {
+        return nil, fmt.Errorf("Invalid 'tz' query parameter: unknown time zone %q", name)
+    }
+    return loc, nil
 }
\ No newline at end of file
@@ -1,15 +1,87 @@
 package handlers
 
 import (
+	"errors"
+	"net/http"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
 	"github.com/go-chi/chi/v5"
+
+	"todo-golang/storage"
 )
 
 
 func (h *TaskHandler) SetupRoutes(r *chi.Mux) {
-    r.Get("/tasks", h.GetTasks)
-    r.Get("/tasks/{id}", h.GetTaskByID)
-    r.Post("/tasks", h.CreateTask)
-    r.Delete("/tasks/{id}", h.DeleteTask)
-    r.Patch("/tasks/{id}/done", h.MarkTaskDone)
-    r.Get("/tasks/filter", h.GetFilteredTasks)
-}
\ No newline at end of file
+    r.Route("/tasks", h.taskRoutes)
+    r.Get("/tags", h.GetTags)
+    r.Get("/trash", h.GetTrash)
+    r.Get("/healthz", h.Health)
+}
+
+// taskRoutes registers the task endpoints relative to a task collection, which
+// is either /tasks or the tasks of one project.
+func (h *TaskHandler) taskRoutes(r chi.Router) {
+    r.Get("/", h.GetTasks)
+    r.Post("/", h.CreateTask)
+    r.Get("/filter", h.GetFilteredTasks)
+    r.Get("/due", h.GetDueTasks)
+    r.Route("/{id}", func(r chi.Router) {
+        r.Post("/restore", h.RestoreTask)
+        r.Group(func(r chi.Router) {
+            r.Use(h.requireTaskInProject)
+            r.Get("/", h.GetTaskByID)
+            r.Put("/", h.UpdateTask)
+            r.Patch("/", h.PatchTask)
+            r.Delete("/", h.DeleteTask)
+            r.Patch("/done", h.MarkTaskDone)
+            r.Patch("/undone", h.ReopenTask)
+            r.Get("/history", h.GetTaskHistory)
+            r.Get("/subtasks", h.GetSubtasks)
+            r.Get("/occurrences", h.GetTaskOccurrences)
+            r.Post("/tags/{tag}", h.AddTaskTag)
+            r.Delete("/tags/{tag}", h.RemoveTaskTag)
+            r.Post("/blockers/{blockerID}", h.AddTaskBlocker)
+            r.Delete("/blockers/{blockerID}", h.RemoveTaskBlocker)
+        })
+    })
+}
+
+// requireTaskInProject answers 404 for tasks outside the project the request
+// is scoped to. Unscoped requests pass through.
+func (h *TaskHandler) requireTaskInProject(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        projectID, scoped := projectFromContext(r.Context())
+        if !scoped {
+            next.ServeHTTP(w, r)
+            return
+        }
+
+        id, err := strconv.Atoi(chi.URLParam(r, "id"))
+        if err != nil {
+            http.Error(w, "Invalid task ID", http.StatusBadRequest)
+            return
+        }
+
+        task, err := h.repo.GetByID(r.Context(), id)
+        if err != nil && !errors.Is(err, storage.ErrNotFound) {
+            http.Error(w, "Failed to fetch task", http.StatusInternalServerError)
+            return
+        }
+        if err != nil || task.ProjectID == nil || *task.ProjectID != projectID {
+            http.Error(w, "Task not found", http.StatusNotFound)
+            return
+        }
+
+        next.ServeHTTP(w, r)
+    })
+}
+
+// errorMessage returns the message of a storage error as shown to clients,
+// capitalised like the other error responses.
+func errorMessage(err error) string {
+    msg := err.Error()
+    r, size := utf8.DecodeRuneInString(msg)
+    return string(unicode.ToUpper(r)) + msg[size:]
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+    "net/http"
+
+    "todo-golang/storage"
+)
+
+// Health
+// @Summary Проверить работоспособность сервера
+// @Description Отвечает 200, если сервер запущен и хранилище задач доступно
+// @Tags health
+// @Produce plain
+// @Success 200 {string} string "ok"
+// @Failure 503 {string} string "Хранилище недоступно"
+// @Router /healthz [get]
+func (h *TaskHandler) Health(w http.ResponseWriter, r *http.Request) {
+    if _, err := h.repo.GetAll(r.Context(), storage.Page{Limit: 1}); err != nil {
+        http.Error(w, "Storage is unavailable", http.StatusServiceUnavailable)
+        return
+    }
+
+    w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+    w.Write([]byte("ok\n"))
+}
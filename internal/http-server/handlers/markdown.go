@@ -0,0 +1,61 @@
+package handlers
+
+import (
+    "bytes"
+    "fmt"
+    "net/http"
+
+    "github.com/microcosm-cc/bluemonday"
+    "github.com/yuin/goldmark"
+    "github.com/yuin/goldmark/extension"
+
+    "todo-golang/internal/model"
+)
+
+var (
+    markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+    // htmlPolicy strips scripts, event handlers and other unsafe markup from
+    // rendered descriptions, which come straight from users.
+    htmlPolicy = bluemonday.UGCPolicy()
+)
+
+// wantsHTML reports whether the request asks for descriptions rendered as HTML
+// (render=html) rather than the raw Markdown returned by default.
+func wantsHTML(r *http.Request) (bool, error) {
+    switch render := r.URL.Query().Get("render"); render {
+    case "", "markdown":
+        return false, nil
+    case "html":
+        return true, nil
+    default:
+        return false, fmt.Errorf("Invalid 'render' query parameter: expected markdown or html")
+    }
+}
+
+// renderDescriptions replaces the Markdown descriptions of tasks with sanitized HTML.
+func renderDescriptions(tasks []model.Task) error {
+    for i := range tasks {
+        var buf bytes.Buffer
+        if err := markdown.Convert([]byte(tasks[i].Description), &buf); err != nil {
+            return err
+        }
+        tasks[i].Description = htmlPolicy.Sanitize(buf.String())
+    }
+    return nil
+}
+
+// renderTreeDescriptions renders the descriptions of a task and all its subtasks.
+func renderTreeDescriptions(tree *model.TaskTree) error {
+    tasks := []model.Task{tree.Task}
+    if err := renderDescriptions(tasks); err != nil {
+        return err
+    }
+    tree.Task = tasks[0]
+
+    for i := range tree.Subtasks {
+        if err := renderTreeDescriptions(&tree.Subtasks[i]); err != nil {
+            return err
+        }
+    }
+    return nil
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+    "encoding/json"
+)
+
+// applyMergePatch applies an RFC 7396 JSON Merge Patch to the JSON document target.
+func applyMergePatch(target, patch []byte) ([]byte, error) {
+    var targetDoc, patchDoc interface{}
+    if err := json.Unmarshal(target, &targetDoc); err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(patch, &patchDoc); err != nil {
+        return nil, err
+    }
+
+    return json.Marshal(mergePatch(targetDoc, patchDoc))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+    patchObj, ok := patch.(map[string]interface{})
+    if !ok {
+        return patch
+    }
+
+    targetObj, ok := target.(map[string]interface{})
+    if !ok {
+        targetObj = make(map[string]interface{})
+    }
+
+    for key, value := range patchObj {
+        if value == nil {
+            delete(targetObj, key)
+            continue
+        }
+        targetObj[key] = mergePatch(targetObj[key], value)
+    }
+
+    return targetObj
+}
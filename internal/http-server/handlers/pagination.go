@@ -0,0 +1,173 @@
+package handlers
+
+import (
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "strconv"
+    "time"
+
+    "todo-golang/internal/filter"
+    "todo-golang/internal/model"
+    "todo-golang/storage"
+)
+
+const (
+    defaultPageLimit = 50
+    maxPageLimit     = 500
+)
+
+// TaskPage is one page of a task listing. NextCursor is empty on the last page.
+type TaskPage struct {
+    Items      []model.Task `json:"items"`
+    NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// pageCursor is the keyset position encoded in the opaque cursor query
+// parameter: the sort it was issued for and the last task's sort values.
+type pageCursor struct {
+    Sort  string            `json:"sort"`
+    After []json.RawMessage `json:"after"`
+}
+
+func encodeCursor(keys []filter.SortKey, task model.Task) string {
+    c := pageCursor{Sort: filter.FormatSort(keys)}
+    for _, value := range filter.SortValues(task, keys) {
+        raw, _ := json.Marshal(value)
+        c.After = append(c.After, raw)
+    }
+
+    data, _ := json.Marshal(c)
+    return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string, keys []filter.SortKey) ([]interface{}, error) {
+    var c pageCursor
+    data, err := base64.RawURLEncoding.DecodeString(s)
+    if err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(data, &c); err != nil {
+        return nil, err
+    }
+    if c.Sort != filter.FormatSort(keys) || len(c.After) != len(keys) {
+        return nil, fmt.Errorf("cursor was issued for a different sort order")
+    }
+
+    values := make([]interface{}, len(keys))
+    for i, key := range keys {
+        value, err := decodeCursorValue(key.Field.Kind, c.After[i])
+        if err != nil {
+            return nil, err
+        }
+        values[i] = value
+    }
+
+    return values, nil
+}
+
+func decodeCursorValue(kind filter.Kind, raw json.RawMessage) (interface{}, error) {
+    var err error
+    switch kind {
+    case filter.KindInt, filter.KindPriority:
+        var v int
+        err = json.Unmarshal(raw, &v)
+        return v, err
+    case filter.KindBool:
+        var v bool
+        err = json.Unmarshal(raw, &v)
+        return v, err
+    case filter.KindTime:
+        var v time.Time
+        err = json.Unmarshal(raw, &v)
+        return v.UTC(), err
+    default:
+        var v string
+        err = json.Unmarshal(raw, &v)
+        return v, err
+    }
+}
+
+// parsePage reads limit, sort and cursor. defaultSort applies when sort is absent.
+func parsePage(r *http.Request, defaultSort []filter.SortKey) (storage.Page, error) {
+    page := storage.Page{Limit: defaultPageLimit}
+
+    if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+        limit, err := strconv.Atoi(limitStr)
+        if err != nil || limit < 1 || limit > maxPageLimit {
+            return page, fmt.Errorf("Invalid 'limit' query parameter: must be between 1 and %d", maxPageLimit)
+        }
+        page.Limit = limit
+    }
+
+    sort, err := filter.ParseSort(r.URL.Query().Get("sort"))
+    if err != nil {
+        return page, fmt.Errorf("Invalid 'sort' query parameter: %w", err)
+    }
+    page.Sort = sort
+    if page.Sort == nil {
+        page.Sort = defaultSort
+    }
+
+    if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
+        after, err := decodeCursor(cursorStr, page.Order())
+        if err != nil {
+            return page, fmt.Errorf("Invalid 'cursor' query parameter")
+        }
+        page.After = after
+    }
+
+    return page, nil
+}
+
+// listTasks serves one page of a task listing produced by fetch. It asks fetch
+// for one extra task to learn whether a next page exists.
+func (h *TaskHandler) listTasks(w http.ResponseWriter, r *http.Request, defaultSort []filter.SortKey, fetch func(storage.Page) ([]model.Task, error)) {
+    page, err := parsePage(r, defaultSort)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    asHTML, err := wantsHTML(r)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    limit := page.Limit
+    page.Limit++
+    tasks, err := fetch(page)
+    if err != nil {
+        http.Error(w, "Failed to fetch tasks", http.StatusInternalServerError)
+        return
+    }
+
+    resp := TaskPage{Items: tasks}
+    if resp.Items == nil {
+        resp.Items = []model.Task{}
+    }
+
+    if len(tasks) > limit {
+        resp.Items = tasks[:limit]
+        resp.NextCursor = encodeCursor(page.Order(), resp.Items[limit-1])
+
+        next := *r.URL
+        query := next.Query()
+        query.Set("cursor", resp.NextCursor)
+        query.Set("limit", strconv.Itoa(limit))
+        next.RawQuery = query.Encode()
+        w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+    }
+
+    if asHTML {
+        if err := renderDescriptions(resp.Items); err != nil {
+            http.Error(w, "Failed to render task descriptions", http.StatusInternalServerError)
+            return
+        }
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+    "encoding/base64"
+    "net/http"
+    "reflect"
+    "testing"
+    "time"
+
+    "todo-golang/internal/filter"
+    "todo-golang/internal/model"
+    "todo-golang/storage"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+    due := time.Date(2030, 1, 1, 9, 30, 0, 0, time.FixedZone("MSK", 3*60*60))
+    task := model.Task{
+        ID:        42,
+        Title:     "Отчет \"Q1\"",
+        Done:      true,
+        Priority:  model.PriorityHigh,
+        DueAt:     &due,
+        CreatedAt: time.Date(2029, 12, 1, 8, 0, 0, 123456789, time.UTC),
+    }
+
+    for _, spec := range []string{"id", "-priority", "done,-due_at", "title,created_at", "completed_at"} {
+        t.Run(spec, func(t *testing.T) {
+            keys := storage.Page{Sort: filter.MustParseSort(spec)}.Order()
+
+            got, err := decodeCursor(encodeCursor(keys, task), keys)
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            want := filter.SortValues(task, keys)
+            for i := range want {
+                if tm, ok := want[i].(time.Time); ok {
+                    want[i] = tm.UTC()
+                }
+            }
+            if !reflect.DeepEqual(got, want) {
+                t.Errorf("decoded %#v, want %#v", got, want)
+            }
+        })
+    }
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+    keys := storage.Page{Sort: filter.MustParseSort("-priority")}.Order()
+    valid := encodeCursor(keys, model.Task{ID: 7, Priority: model.PriorityHigh})
+    raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+    tests := []struct {
+        name   string
+        cursor string
+    }{
+        {"not base64", "!!!"},
+        {"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"sort":"-priority,id","after":[3,7]}`))},
+        {"not JSON", raw("sort=-priority,id")},
+        {"truncated", valid[:len(valid)-4]},
+        {"other sort", encodeCursor(storage.Page{Sort: filter.MustParseSort("priority")}.Order(), model.Task{ID: 7})},
+        {"sort renamed", raw(`{"sort":"-due_at,id","after":[3,7]}`)},
+        {"too few values", raw(`{"sort":"-priority,id","after":[3]}`)},
+        {"too many values", raw(`{"sort":"-priority,id","after":[3,7,9]}`)},
+        {"wrong type", raw(`{"sort":"-priority,id","after":["high",7]}`)},
+        {"fractional id", raw(`{"sort":"-priority,id","after":[3,7.5]}`)},
+    }
+
+    if _, err := decodeCursor(valid, keys); err != nil {
+        t.Fatalf("valid cursor rejected: %v", err)
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if after, err := decodeCursor(tt.cursor, keys); err == nil {
+                t.Errorf("decodeCursor(%q) = %v, want error", tt.cursor, after)
+            }
+        })
+    }
+}
+
+func TestCursorForOtherSortIsRejected(t *testing.T) {
+    repo := storage.NewMemoryTaskRepository()
+    router := newTestRouter(repo)
+    for _, title := range []string{"a", "b", "c"} {
+        if rec := serve(t, router, http.MethodPost, "/tasks", `{"title":"`+title+`"}`); rec.Code != http.StatusCreated {
+            t.Fatalf("POST /tasks = %d", rec.Code)
+        }
+    }
+
+    keys := storage.Page{Sort: filter.MustParseSort("title")}.Order()
+    cursor := encodeCursor(keys, model.Task{ID: 1, Title: "a"})
+
+    if rec := serve(t, router, http.MethodGet, "/tasks?sort=title&limit=1&cursor="+cursor, ""); rec.Code != http.StatusOK {
+        t.Errorf("cursor for the same sort: %d %q", rec.Code, rec.Body.String())
+    }
+    if rec := serve(t, router, http.MethodGet, "/tasks?sort=-title&limit=1&cursor="+cursor, ""); rec.Code != http.StatusBadRequest {
+        t.Errorf("cursor for another sort: %d, want %d", rec.Code, http.StatusBadRequest)
+    }
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+
+    "github.com/go-chi/chi/v5"
+
+    "todo-golang/internal/model"
+    "todo-golang/storage"
+)
+
+// maxProjectNameLength matches the VARCHAR(255) name column.
+const maxProjectNameLength = 255
+
+type ProjectHandler struct {
+    repo  storage.ProjectRepository
+    tasks *TaskHandler
+}
+
+// NewProjectHandler returns a handler for the project endpoints. The task
+// endpoints of tasks are also served per project under /projects/{id}/tasks.
+func NewProjectHandler(repo storage.ProjectRepository, tasks *TaskHandler) *ProjectHandler {
+    return &ProjectHandler{repo: repo, tasks: tasks}
+}
+
+func (h *ProjectHandler) SetupRoutes(r *chi.Mux) {
+    r.Get("/projects", h.GetProjects)
+    r.Post("/projects", h.CreateProject)
+    r.Get("/projects/{projectID}", h.GetProjectByID)
+    r.Put("/projects/{projectID}", h.UpdateProject)
+    r.Patch("/projects/{projectID}", h.PatchProject)
+    r.Delete("/projects/{projectID}", h.DeleteProject)
+    r.Route("/projects/{projectID}/tasks", func(r chi.Router) {
+        r.Use(h.withProject)
+        h.tasks.taskRoutes(r)
+    })
+}
+
+type projectKey struct{}
+
+// projectFromContext returns the project a request is scoped to, if any.
+func projectFromContext(ctx context.Context) (int, bool) {
+    id, ok := ctx.Value(projectKey{}).(int)
+    return id, ok
+}
+
+// withProject scopes the request to the project in the URL, answering 404 if
+// it does not exist.
+func (h *ProjectHandler) withProject(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        project, ok := h.fetchProject(w, r)
+        if !ok {
+            return
+        }
+
+        ctx := context.WithValue(r.Context(), projectKey{}, project.ID)
+        next.ServeHTTP(w, r.WithContext(ctx))
+    })
+}
+
+// fetchProject loads the project named in the URL. It writes the error
+// response and returns false if the project cannot be loaded.
+func (h *ProjectHandler) fetchProject(w http.ResponseWriter, r *http.Request) (model.Project, bool) {
+    id, err := strconv.Atoi(chi.URLParam(r, "projectID"))
+    if err != nil {
+        http.Error(w, "Invalid project ID", http.StatusBadRequest)
+        return model.Project{}, false
+    }
+
+    project, err := h.repo.GetProject(r.Context(), id)
+    if err != nil {
+        if errors.Is(err, storage.ErrNotFound) {
+            http.Error(w, "Project not found", http.StatusNotFound)
+            return model.Project{}, false
+        }
+        http.Error(w, "Failed to fetch project", http.StatusInternalServerError)
+        return model.Project{}, false
+    }
+
+    return project, true
+}
+
+// GetProjects
+// @Summary Получить список проектов
+// @Description Возвращает все проекты
+// @Tags projects
+// @Produce json
+// @Success 200 {array} model.Project "Список проектов"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /projects [get]
+func (h *ProjectHandler) GetProjects(w http.ResponseWriter, r *http.Request) {
+    projects, err := h.repo.GetProjects(r.Context())
+    if err != nil {
+        http.Error(w, "Failed to fetch projects", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(projects)
+}
+
+// GetProjectByID
+// @Summary Получить проект по идентификатору
+// @Description Возвращает проект по указанному идентификатору. Задачи проекта доступны по адресу /projects/{id}/tasks
+// @Tags projects
+// @Produce json
+// @Param id path int true "ID проекта"
+// @Success 200 {object} model.Project "Проект"
+// @Failure 400 {object} map[string]string "Некорректный идентификатор проекта"
+// @Failure 404 {object} map[string]string "Проект не найден"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /projects/{id} [get]
+func (h *ProjectHandler) GetProjectByID(w http.ResponseWriter, r *http.Request) {
+    project, ok := h.fetchProject(w, r)
+    if !ok {
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(project)
+}
+
+// CreateProject
+// @Summary Создать проект
+// @Description Добавляет новый проект. Если политика удаления не указана, используется block
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param project body model.Project true "Создание проекта"
+// @Success 201 {object} model.Project "Созданный проект"
+// @Header 201 {string} Location "URL созданного проекта"
+// @Failure 400 {object} map[string]string "Некорректные данные"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /projects [post]
+func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
+    var project model.Project
+    if err := json.NewDecoder(r.Body).Decode(&project); err != nil {
+        http.Error(w, "Invalid input", http.StatusBadRequest)
+        return
+    }
+
+    if project.ID != 0 {
+        http.Error(w, "Project ID is assigned by the server", http.StatusBadRequest)
+        return
+    }
+    if project.DeletePolicy == "" {
+        project.DeletePolicy = model.ProjectDeleteBlock
+    }
+    if err := validateProject(project); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    created, err := h.repo.AddProject(r.Context(), project)
+    if err != nil {
+        http.Error(w, "Failed to add project", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.Header().Set("Location", fmt.Sprintf("/projects/%d", created.ID))
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(created)
+}
+
+// UpdateProject
+// @Summary Заменить проект
+// @Description Полностью заменяет поля проекта по идентификатору
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path int true "ID проекта"
+// @Param project body model.Project true "Новое состояние проекта"
+// @Success 200 {object} model.Project "Обновленный проект"
+// @Failure 400 {object} map[string]string "Некорректные данные"
+// @Failure 404 {object} map[string]string "Проект не найден"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /projects/{id} [put]
+func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(chi.URLParam(r, "projectID"))
+    if err != nil {
+        http.Error(w, "Invalid project ID", http.StatusBadRequest)
+        return
+    }
+
+    var project model.Project
+    if err := json.NewDecoder(r.Body).Decode(&project); err != nil {
+        http.Error(w, "Invalid input", http.StatusBadRequest)
+        return
+    }
+
+    if project.ID != 0 && project.ID != id {
+        http.Error(w, "Project ID in body does not match URL", http.StatusBadRequest)
+        return
+    }
+    project.ID = id
+    if project.DeletePolicy == "" {
+        project.DeletePolicy = model.ProjectDeleteBlock
+    }
+
+    h.saveProject(w, r, project)
+}
+
+// PatchProject
+// @Summary Частично обновить проект
+// @Description Изменяет переданные поля проекта (JSON Merge Patch, RFC 7396)
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path int true "ID проекта"
+// @Param patch body object true "Изменяемые поля проекта"
+// @Success 200 {object} model.Project "Обновленный проект"
+// @Failure 400 {object} map[string]string "Некорректные данные"
+// @Failure 404 {object} map[string]string "Проект не найден"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /projects/{id} [patch]
+func (h *ProjectHandler) PatchProject(w http.ResponseWriter, r *http.Request) {
+    current, ok := h.fetchProject(w, r)
+    if !ok {
+        return
+    }
+
+    patch, err := io.ReadAll(r.Body)
+    if err != nil {
+        http.Error(w, "Invalid input", http.StatusBadRequest)
+        return
+    }
+
+    currentJSON, err := json.Marshal(current)
+    if err != nil {
+        http.Error(w, "Failed to update project", http.StatusInternalServerError)
+        return
+    }
+
+    patchedJSON, err := applyMergePatch(currentJSON, patch)
+    if err != nil {
+        http.Error(w, "Invalid input", http.StatusBadRequest)
+        return
+    }
+
+    var project model.Project
+    if err := json.Unmarshal(patchedJSON, &project); err != nil {
+        http.Error(w, "Invalid input", http.StatusBadRequest)
+        return
+    }
+
+    if project.ID != current.ID {
+        http.Error(w, "Project ID cannot be changed", http.StatusBadRequest)
+        return
+    }
+
+    h.saveProject(w, r, project)
+}
+
+func (h *ProjectHandler) saveProject(w http.ResponseWriter, r *http.Request, project model.Project) {
+    if err := validateProject(project); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    updated, err := h.repo.UpdateProject(r.Context(), project)
+    if err != nil {
+        if errors.Is(err, storage.ErrNotFound) {
+            http.Error(w, "Project not found", http.StatusNotFound)
+            return
+        }
+        http.Error(w, "Failed to update project", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(updated)
+}
+
+// DeleteProject
+// @Summary Удалить проект
+// @Description Удаляет проект. При политике block проект с задачами не удаляется, при политике cascade его задачи вместе с подзадачами перемещаются в корзину. Задачи проекта в корзине остаются в ней без проекта
+// @Tags projects
+// @Param id path int true "ID проекта"
+// @Success 204 "Проект успешно удален"
+// @Failure 400 {object} map[string]string "Некорректный идентификатор проекта"
+// @Failure 404 {object} map[string]string "Проект не найден"
+// @Failure 409 {object} map[string]string "В проекте есть задачи"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /projects/{id} [delete]
+func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(chi.URLParam(r, "projectID"))
+    if err != nil {
+        http.Error(w, "Invalid project ID", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.repo.DeleteProject(r.Context(), id); err != nil {
+        switch {
+        case errors.Is(err, storage.ErrNotFound):
+            http.Error(w, "Project not found", http.StatusNotFound)
+        case errors.Is(err, storage.ErrProjectHasTasks):
+            http.Error(w, "Project has tasks; delete or move them first, or set its delete_policy to cascade", http.StatusConflict)
+        default:
+            http.Error(w, "Failed to delete project", http.StatusInternalServerError)
+        }
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func validateProject(project model.Project) error {
+    if strings.TrimSpace(project.Name) == "" {
+        return fmt.Errorf("Name is required")
+    }
+    if utf8.RuneCountInString(project.Name) > maxProjectNameLength {
+        return fmt.Errorf("Name must be at most %d characters", maxProjectNameLength)
+    }
+    if project.DeletePolicy != model.ProjectDeleteBlock && project.DeletePolicy != model.ProjectDeleteCascade {
+        return fmt.Errorf("Delete policy must be block or cascade")
+    }
+
+    return nil
+}
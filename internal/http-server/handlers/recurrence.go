@@ -0,0 +1,107 @@
+package handlers
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/go-chi/chi/v5"
+
+    "todo-golang/internal/filter"
+    "todo-golang/internal/model"
+    "todo-golang/internal/recurrence"
+    "todo-golang/storage"
+)
+
+const (
+    defaultOccurrencesWindow = 30 * 24 * time.Hour
+    maxOccurrences           = 100
+)
+
+// GetTaskOccurrences
+// @Summary Предпросмотр повторений задачи
+// @Description Возвращает сроки экземпляров повторяющейся задачи в интервале [from, to], начиная с ее текущего срока (не более 100)
+// @Tags tasks
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param from query string false "Начало интервала: дата (2006-01-02) или RFC 3339 (по умолчанию текущий момент)"
+// @Param to query string false "Конец интервала: дата (2006-01-02) или RFC 3339 (по умолчанию from + 30 дней)"
+// @Param tz query string false "Часовой пояс IANA для дат без времени, например Europe/Moscow (по умолчанию UTC)"
+// @Success 200 {array} model.Occurrence "Экземпляры задачи"
+// @Failure 400 {object} map[string]string "Некорректные параметры запроса или задача не повторяется"
+// @Failure 404 {object} map[string]string "Задача не найдена"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /tasks/{id}/occurrences [get]
+func (h *TaskHandler) GetTaskOccurrences(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid task ID", http.StatusBadRequest)
+        return
+    }
+
+    from, to, err := parseOccurrencesRange(r)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    task, err := h.repo.GetByID(r.Context(), id)
+    if err != nil {
+        if errors.Is(err, storage.ErrNotFound) {
+            http.Error(w, "Task not found", http.StatusNotFound)
+            return
+        }
+        http.Error(w, "Failed to fetch task", http.StatusInternalServerError)
+        return
+    }
+
+    if task.Recurrence == "" || task.DueAt == nil {
+        http.Error(w, "Task is not recurring", http.StatusBadRequest)
+        return
+    }
+
+    rule, err := recurrence.Parse(task.Recurrence)
+    if err != nil {
+        http.Error(w, "Failed to parse task recurrence", http.StatusInternalServerError)
+        return
+    }
+
+    occurrences := []model.Occurrence{}
+    for _, due := range rule.Between(*task.DueAt, from, to, maxOccurrences) {
+        occurrences = append(occurrences, model.Occurrence{DueAt: due})
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(occurrences)
+}
+
+// parseOccurrencesRange reads the from and to query parameters. Plain dates
+// are read in the time zone given by tz.
+func parseOccurrencesRange(r *http.Request) (time.Time, time.Time, error) {
+    loc, err := parseLocation(r)
+    if err != nil {
+        return time.Time{}, time.Time{}, err
+    }
+
+    from := time.Now().UTC()
+    if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+        if from, err = filter.ParseTime(fromStr, loc); err != nil {
+            return time.Time{}, time.Time{}, fmt.Errorf("Invalid 'from' query parameter: %v", err)
+        }
+    }
+
+    to := from.Add(defaultOccurrencesWindow)
+    if toStr := r.URL.Query().Get("to"); toStr != "" {
+        if to, err = filter.ParseTime(toStr, loc); err != nil {
+            return time.Time{}, time.Time{}, fmt.Errorf("Invalid 'to' query parameter: %v", err)
+        }
+    }
+
+    if to.Before(from) {
+        return time.Time{}, time.Time{}, fmt.Errorf("Invalid range: 'to' is before 'from'")
+    }
+    return from, to, nil
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+    "encoding/json"
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/go-chi/chi/v5"
+
+    "todo-golang/internal/filter"
+    "todo-golang/internal/model"
+    "todo-golang/storage"
+)
+
+// GetSubtasks
+// @Summary Получить подзадачи
+// @Description Возвращает прямые подзадачи задачи постранично; поддерживает те же параметры фильтрации и сортировки, что и GET /tasks
+// @Tags tasks
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param q query string false "Дополнительное выражение фильтра"
+// @Param sort query string false "Сортировка (по умолчанию id)"
+// @Param limit query int false "Размер страницы (1-500, по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param render query string false "Формат описаний: markdown (по умолчанию) или html"
+// @Success 200 {object} TaskPage "Страница списка подзадач"
+// @Header 200 {string} Link "Ссылка на следующую страницу (rel=next)"
+// @Failure 400 {object} map[string]string "Некорректные параметры запроса"
+// @Failure 404 {object} map[string]string "Задача не найдена"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /tasks/{id}/subtasks [get]
+func (h *TaskHandler) GetSubtasks(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid task ID", http.StatusBadRequest)
+        return
+    }
+
+    if _, err := h.repo.GetByID(r.Context(), id); err != nil {
+        if errors.Is(err, storage.ErrNotFound) {
+            http.Error(w, "Task not found", http.StatusNotFound)
+            return
+        }
+        http.Error(w, "Failed to fetch task", http.StatusInternalServerError)
+        return
+    }
+
+    expr, err := parseFilterQuery(r)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    expr = filter.AllOf(filter.Equal("parent_id", id), expr)
+    h.listTasks(w, r, nil, func(page storage.Page) ([]model.Task, error) {
+        return h.repo.GetFiltered(r.Context(), expr, page)
+    })
+}
+
+func (h *TaskHandler) getTaskTree(w http.ResponseWriter, r *http.Request, id int, asHTML bool) {
+    tree, err := h.repo.Subtree(r.Context(), id)
+    if err != nil {
+        if errors.Is(err, storage.ErrNotFound) {
+            http.Error(w, "Task not found", http.StatusNotFound)
+            return
+        }
+        http.Error(w, "Failed to fetch task", http.StatusInternalServerError)
+        return
+    }
+
+    if asHTML {
+        if err := renderTreeDescriptions(&tree); err != nil {
+            http.Error(w, "Failed to render task descriptions", http.StatusInternalServerError)
+            return
+        }
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(tree)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/url"
+    "strconv"
+    "strings"
+
+    "github.com/go-chi/chi/v5"
+
+    "todo-golang/internal/filter"
+    "todo-golang/internal/model"
+    "todo-golang/storage"
+)
+
+// GetTags
+// @Summary Получить список тегов
+// @Description Возвращает все используемые теги и количество задач с каждым из них
+// @Tags tags
+// @Produce json
+// @Success 200 {array} model.TagCount "Список тегов"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /tags [get]
+func (h *TaskHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+    tags, err := h.repo.Tags(r.Context())
+    if err != nil {
+        http.Error(w, "Failed to fetch tags", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(tags)
+}
+
+// AddTaskTag
+// @Summary Добавить тег задаче
+// @Description Помечает задачу тегом; тег создается при первом использовании
+// @Tags tags
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param tag path string true "Тег"
+// @Success 200 {object} model.Task "Задача с обновленным списком тегов"
+// @Failure 400 {object} map[string]string "Некорректный идентификатор задачи или тег"
+// @Failure 404 {object} map[string]string "Задача не найдена"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /tasks/{id}/tags/{tag} [post]
+func (h *TaskHandler) AddTaskTag(w http.ResponseWriter, r *http.Request) {
+    id, tag, ok := parseTaskTag(w, r)
+    if !ok {
+        return
+    }
+
+    if err := h.repo.AddTag(r.Context(), id, tag); err != nil {
+        if errors.Is(err, storage.ErrNotFound) {
+            http.Error(w, "Task not found", http.StatusNotFound)
+            return
+        }
+        http.Error(w, "Failed to tag task", http.StatusInternalServerError)
+        return
+    }
+
+    task, err := h.repo.GetByID(r.Context(), id)
+    if err != nil {
+        http.Error(w, "Failed to fetch task", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(task)
+}
+
+// RemoveTaskTag
+// @Summary Снять тег с задачи
+// @Description Удаляет тег из списка тегов задачи
+// @Tags tags
+// @Param id path int true "ID задачи"
+// @Param tag path string true "Тег"
+// @Success 204 "Тег снят"
+// @Failure 400 {object} map[string]string "Некорректный идентификатор задачи или тег"
+// @Failure 404 {object} map[string]string "Задача не найдена"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /tasks/{id}/tags/{tag} [delete]
+func (h *TaskHandler) RemoveTaskTag(w http.ResponseWriter, r *http.Request) {
+    id, tag, ok := parseTaskTag(w, r)
+    if !ok {
+        return
+    }
+
+    if err := h.repo.RemoveTag(r.Context(), id, tag); err != nil {
+        if errors.Is(err, storage.ErrNotFound) {
+            http.Error(w, "Task not found", http.StatusNotFound)
+            return
+        }
+        http.Error(w, "Failed to untag task", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// parseTaskTag reads the task ID and the normalized tag from the URL. It writes
+// the error response and returns false if either is invalid.
+func parseTaskTag(w http.ResponseWriter, r *http.Request) (int, string, bool) {
+    id, err := strconv.Atoi(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid task ID", http.StatusBadRequest)
+        return 0, "", false
+    }
+
+    tag, err := url.PathUnescape(chi.URLParam(r, "tag"))
+    if err != nil {
+        http.Error(w, "Invalid tag", http.StatusBadRequest)
+        return 0, "", false
+    }
+
+    tag, err = model.NormalizeTag(tag)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return 0, "", false
+    }
+
+    return id, tag, true
+}
+
+// parseTagFilter builds a condition from a comma-separated tag list: tasks
+// carrying every tag, or any of them if anyOf is set.
+func parseTagFilter(list string, anyOf bool) (filter.Expr, error) {
+    var conds []filter.Expr
+    for _, tag := range strings.Split(list, ",") {
+        tag, err := model.NormalizeTag(tag)
+        if err != nil {
+            return nil, err
+        }
+        conds = append(conds, filter.Equal("tag", tag))
+    }
+
+    if anyOf {
+        return filter.AnyOf(conds...), nil
+    }
+    return filter.AllOf(conds...), nil
+}
@@ -2,41 +2,76 @@ package handlers
 
 import (
     "encoding/json"
+    "errors"
+    "fmt"
+    "io"
     "net/http"
     "strconv"
+    "strings"
+    "unicode/utf8"
 
     "github.com/go-chi/chi/v5"
 
-    "todo-golang/internal/config"
+    "todo-golang/internal/filter"
+    "todo-golang/internal/model"
+    "todo-golang/internal/recurrence"
+    "todo-golang/storage"
 )
 
+const (
+    // maxTitleLength matches the VARCHAR(255) title column.
+    maxTitleLength       = 255
+    maxDescriptionLength = 20000
+)
+
+// defaultTaskSort puts open tasks first, most urgent at the top.
+var defaultTaskSort = filter.MustParseSort("done,-priority")
+
+// actorHeader names the user on whose behalf a request is made; it is recorded in task history.
+const actorHeader = "X-User"
+
 // GetTasks
 // @Summary Получить список задач
-// @Description Возвращает список всех задач
+// @Description Возвращает список всех задач постранично (keyset-пагинация). По умолчанию первыми идут невыполненные задачи с наибольшим приоритетом
 // @Tags tasks
 // @Accept json
 // @Produce json
-// @Success 200 {array} model.Task "Список задач"
+// @Param q query string false "Выражение фильтра, например: done:false title~\"deploy\" id>10"
+// @Param overdue query bool false "true - только просроченные невыполненные задачи, false - все остальные"
+// @Param tags_all query string false "Теги через запятую: только задачи со всеми тегами"
+// @Param tags_any query string false "Теги через запятую: задачи хотя бы с одним из тегов"
+// @Param tz query string false "Часовой пояс IANA для дат без времени в q, например Europe/Moscow (по умолчанию UTC)"
+// @Param sort query string false "Сортировка: поля через запятую, '-' - по убыванию (id, title, done, priority, due_at, created_at, updated_at, completed_at); по умолчанию done,-priority"
+// @Param limit query int false "Размер страницы (1-500, по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param render query string false "Формат описаний: markdown (по умолчанию) или html"
+// @Success 200 {object} TaskPage "Страница списка задач"
+// @Header 200 {string} Link "Ссылка на следующую страницу (rel=next)"
+// @Failure 400 {object} map[string]string "Некорректный фильтр или параметры пагинации"
 // @Failure 500 {object} map[string]string "Ошибка сервера"
 // @Router /tasks [get]
 func (h *TaskHandler) GetTasks(w http.ResponseWriter, r *http.Request) {
-    tasks, err := h.repo.GetAll()
+    expr, err := parseFilterQuery(r)
     if err != nil {
-        http.Error(w, "Failed to fetch tasks", http.StatusInternalServerError)
+        http.Error(w, err.Error(), http.StatusBadRequest)
         return
     }
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(tasks)
+
+    h.listTasks(w, r, defaultTaskSort, func(page storage.Page) ([]model.Task, error) {
+        return h.repo.GetFiltered(r.Context(), expr, page)
+    })
 }
 
 // GetTaskByID
 // @Summary Получить задачу по идентификатору
-// @Description Возвращает задачу по указанному идентификатору
+// @Description Возвращает задачу по указанному идентификатору. С параметром expand=tree возвращает задачу вместе со всеми подзадачами (model.TaskTree)
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param id path int true "ID задачи"
-// @Success 200 {object} model.Task "Задача"
+// @Param render query string false "Формат описания: markdown (по умолчанию) или html"
+// @Param expand query string false "tree - включить дерево подзадач"
+// @Success 200 {object} model.TaskTree "Задача (поле subtasks только при expand=tree)"
 // @Failure 404 {object} map[string]string "Задача не найдена"
 // @Failure 500 {object} map[string]string "Ошибка сервера"
 // @Router /tasks/{id} [get]
@@ -48,9 +83,25 @@ func (h *TaskHandler) GetTaskByID(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    task, err := h.repo.GetByID(id)
+    asHTML, err := wantsHTML(r)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    switch r.URL.Query().Get("expand") {
+    case "":
+    case "tree":
+        h.getTaskTree(w, r, id, asHTML)
+        return
+    default:
+        http.Error(w, "Invalid 'expand' query parameter: must be tree", http.StatusBadRequest)
+        return
+    }
+
+    task, err := h.repo.GetByID(r.Context(), id)
     if err != nil {
-        if err.Error() == "task not found" {
+        if errors.Is(err, storage.ErrNotFound) {
             http.Error(w, "Task not found", http.StatusNotFound)
             return
         }
@@ -58,45 +109,83 @@ func (h *TaskHandler) GetTaskByID(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+    if asHTML {
+        tasks := []model.Task{task}
+        if err := renderDescriptions(tasks); err != nil {
+            http.Error(w, "Failed to render task description", http.StatusInternalServerError)
+            return
+        }
+        task = tasks[0]
+    }
+
     w.Header().Set("Content-Type", "application/json")
     json.NewEncoder(w).Encode(task)
 }
 
 // CreateTask
 // @Summary Создать новую задачу
-// @Description Добавляет новую задачу
+// @Description Добавляет новую задачу. Если приоритет не указан, используется normal
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param task body model.Task true "Создание задачи"
 // @Success 201 {object} model.Task "Созданная задача"
+// @Header 201 {string} Location "URL созданной задачи"
 // @Failure 400 {object} map[string]string "Некорректные данные"
 // @Failure 500 {object} map[string]string "Ошибка сервера"
 // @Router /tasks [post]
 func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
     var task model.Task
     if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
-        http.Error(w, "Invalid input", http.StatusBadRequest)
+        http.Error(w, decodeError(err), http.StatusBadRequest)
         return
     }
 
-    if err := h.repo.Add(task); err != nil {
+    if task.ID != 0 {
+        http.Error(w, "Task ID is assigned by the server", http.StatusBadRequest)
+        return
+    }
+    if projectID, ok := projectFromContext(r.Context()); ok {
+        if task.ProjectID != nil && *task.ProjectID != projectID {
+            http.Error(w, "Task project_id does not match URL", http.StatusBadRequest)
+            return
+        }
+        task.ProjectID = &projectID
+    }
+    if task.Priority == 0 {
+        task.Priority = model.PriorityNormal
+    }
+    if err := validateTask(task); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    created, err := h.repo.Add(r.Context(), task)
+    if err != nil {
+        if errors.Is(err, storage.ErrValidation) {
+            http.Error(w, errorMessage(err), http.StatusBadRequest)
+            return
+        }
         http.Error(w, "Failed to add task", http.StatusInternalServerError)
         return
     }
 
+    w.Header().Set("Content-Type", "application/json")
+    w.Header().Set("Location", fmt.Sprintf("/tasks/%d", created.ID))
     w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(created)
 }
 
 // DeleteTask
 // @Summary Удалить задачу
-// @Description Удаляет задачу по идентификатору
+// @Description Перемещает задачу вместе с подзадачами в корзину, откуда ее можно восстановить до окончательного удаления
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param id path int true "ID задачи"
-// @Success 204 "Задача успешно удалена"
+// @Success 204 "Задача перемещена в корзину"
 // @Failure 400 {object} map[string]string "Некорректный идентификатор задачи"
+// @Failure 404 {object} map[string]string "Задача не найдена"
 // @Failure 500 {object} map[string]string "Ошибка сервера"
 // @Router /tasks/{id} [delete]
 func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
@@ -107,7 +196,11 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    if err := h.repo.Delete(id); err != nil {
+    if err := h.repo.Delete(r.Context(), id); err != nil {
+        if errors.Is(err, storage.ErrNotFound) {
+            http.Error(w, "Task not found", http.StatusNotFound)
+            return
+        }
         http.Error(w, "Failed to delete task", http.StatusInternalServerError)
         return
     }
@@ -117,13 +210,18 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 
 // MarkTaskDone
 // @Summary Пометить задачу как выполненную
-// @Description Помечает задачу как выполненную по идентификатору
+// @Description Помечает задачу как выполненную по идентификатору. Для повторяющейся задачи создается ее следующий экземпляр
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param id path int true "ID задачи"
+// @Param X-User header string false "Пользователь, выполняющий действие"
+// @Param cascade query bool false "true - выполнить вместе с задачей все ее невыполненные подзадачи; иначе при наличии таких подзадач возвращается 409"
+// @Param force query bool false "true - выполнить задачу, даже если ее блокируют невыполненные задачи; иначе возвращается 409"
 // @Success 200 {object} model.Task "Задача помечена как выполненная"
 // @Failure 400 {object} map[string]string "Некорректный идентификатор задачи"
+// @Failure 404 {object} map[string]string "Задача не найдена"
+// @Failure 409 {object} map[string]string "У задачи есть невыполненные подзадачи или блокирующие задачи"
 // @Failure 500 {object} map[string]string "Ошибка сервера"
 // @Router /tasks/{id}/done [patch]
 func (h *TaskHandler) MarkTaskDone(w http.ResponseWriter, r *http.Request) {
@@ -134,10 +232,308 @@ func (h *TaskHandler) MarkTaskDone(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    if err := h.repo.MarkDone(id); err != nil {
-        http.Error(w, "Failed to mark task as done", http.StatusInternalServerError)
+    var opts storage.DoneOptions
+    if opts.Cascade, err = queryFlag(r, "cascade"); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    if opts.Force, err = queryFlag(r, "force"); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    if err := h.repo.MarkDone(r.Context(), id, actorFromRequest(r), opts); err != nil {
+        switch {
+        case errors.Is(err, storage.ErrNotFound):
+            http.Error(w, "Task not found", http.StatusNotFound)
+        case errors.Is(err, storage.ErrOpenSubtasks):
+            http.Error(w, "Task has open subtasks; complete them first or pass cascade=true", http.StatusConflict)
+        case errors.Is(err, storage.ErrBlocked):
+            http.Error(w, "Task is blocked by open tasks; complete them first or pass force=true", http.StatusConflict)
+        default:
+            http.Error(w, "Failed to mark task as done", http.StatusInternalServerError)
+        }
         return
     }
 
     w.WriteHeader(http.StatusOK)
 }
+
+// queryFlag parses an optional boolean query parameter, which defaults to false.
+func queryFlag(r *http.Request, name string) (bool, error) {
+    value := r.URL.Query().Get(name)
+    if value == "" {
+        return false, nil
+    }
+
+    flag, err := strconv.ParseBool(value)
+    if err != nil {
+        return false, fmt.Errorf("Invalid '%s' query parameter", name)
+    }
+    return flag, nil
+}
+
+// ReopenTask
+// @Summary Вернуть задачу в работу
+// @Description Снимает отметку о выполнении и записывает, кто и когда переоткрыл задачу
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param X-User header string false "Пользователь, выполняющий действие"
+// @Success 200 "Задача переоткрыта"
+// @Failure 400 {object} map[string]string "Некорректный идентификатор задачи"
+// @Failure 404 {object} map[string]string "Задача не найдена"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /tasks/{id}/undone [patch]
+func (h *TaskHandler) ReopenTask(w http.ResponseWriter, r *http.Request) {
+    idStr := chi.URLParam(r, "id")
+    id, err := strconv.Atoi(idStr)
+    if err != nil {
+        http.Error(w, "Invalid task ID", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.repo.Reopen(r.Context(), id, actorFromRequest(r)); err != nil {
+        if errors.Is(err, storage.ErrNotFound) {
+            http.Error(w, "Task not found", http.StatusNotFound)
+            return
+        }
+        http.Error(w, "Failed to reopen task", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusOK)
+}
+
+// GetTaskHistory
+// @Summary Получить историю задачи
+// @Description Возвращает изменения статуса задачи: кто и когда выполнил или переоткрыл ее
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 200 {array} model.TaskEvent "История задачи"
+// @Failure 400 {object} map[string]string "Некорректный идентификатор задачи"
+// @Failure 404 {object} map[string]string "Задача не найдена"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /tasks/{id}/history [get]
+func (h *TaskHandler) GetTaskHistory(w http.ResponseWriter, r *http.Request) {
+    idStr := chi.URLParam(r, "id")
+    id, err := strconv.Atoi(idStr)
+    if err != nil {
+        http.Error(w, "Invalid task ID", http.StatusBadRequest)
+        return
+    }
+
+    events, err := h.repo.History(r.Context(), id)
+    if err != nil {
+        if errors.Is(err, storage.ErrNotFound) {
+            http.Error(w, "Task not found", http.StatusNotFound)
+            return
+        }
+        http.Error(w, "Failed to fetch task history", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(events)
+}
+
+// UpdateTask
+// @Summary Заменить задачу
+// @Description Полностью заменяет поля задачи по идентификатору. Изменение поля done записывается в историю задачи
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param X-User header string false "Пользователь, выполняющий действие"
+// @Param task body model.Task true "Новое состояние задачи"
+// @Success 200 {object} model.Task "Обновленная задача"
+// @Failure 400 {object} map[string]string "Некорректные данные"
+// @Failure 404 {object} map[string]string "Задача не найдена"
+// @Failure 409 {object} map[string]string "Задачу нельзя выполнить: есть невыполненные подзадачи или блокирующие задачи"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /tasks/{id} [put]
+func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
+    idStr := chi.URLParam(r, "id")
+    id, err := strconv.Atoi(idStr)
+    if err != nil {
+        http.Error(w, "Invalid task ID", http.StatusBadRequest)
+        return
+    }
+
+    var task model.Task
+    if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
+        http.Error(w, decodeError(err), http.StatusBadRequest)
+        return
+    }
+
+    if task.ID != 0 && task.ID != id {
+        http.Error(w, "Task ID in body does not match URL", http.StatusBadRequest)
+        return
+    }
+    task.ID = id
+    if task.Priority == 0 {
+        task.Priority = model.PriorityNormal
+    }
+    if projectID, ok := projectFromContext(r.Context()); ok && task.ProjectID == nil {
+        task.ProjectID = &projectID
+    }
+
+    h.saveTask(w, r, task)
+}
+
+// PatchTask
+// @Summary Частично обновить задачу
+// @Description Изменяет переданные поля задачи (JSON Merge Patch, RFC 7396). Изменение поля done записывается в историю задачи
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param X-User header string false "Пользователь, выполняющий действие"
+// @Param patch body object true "Изменяемые поля задачи"
+// @Success 200 {object} model.Task "Обновленная задача"
+// @Failure 400 {object} map[string]string "Некорректные данные"
+// @Failure 404 {object} map[string]string "Задача не найдена"
+// @Failure 409 {object} map[string]string "Задачу нельзя выполнить: есть невыполненные подзадачи или блокирующие задачи"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /tasks/{id} [patch]
+func (h *TaskHandler) PatchTask(w http.ResponseWriter, r *http.Request) {
+    idStr := chi.URLParam(r, "id")
+    id, err := strconv.Atoi(idStr)
+    if err != nil {
+        http.Error(w, "Invalid task ID", http.StatusBadRequest)
+        return
+    }
+
+    patch, err := io.ReadAll(r.Body)
+    if err != nil {
+        http.Error(w, "Invalid input", http.StatusBadRequest)
+        return
+    }
+
+    current, err := h.repo.GetByID(r.Context(), id)
+    if err != nil {
+        if errors.Is(err, storage.ErrNotFound) {
+            http.Error(w, "Task not found", http.StatusNotFound)
+            return
+        }
+        http.Error(w, "Failed to fetch task", http.StatusInternalServerError)
+        return
+    }
+
+    currentJSON, err := json.Marshal(current)
+    if err != nil {
+        http.Error(w, "Failed to update task", http.StatusInternalServerError)
+        return
+    }
+
+    patchedJSON, err := applyMergePatch(currentJSON, patch)
+    if err != nil {
+        http.Error(w, "Invalid input", http.StatusBadRequest)
+        return
+    }
+
+    var task model.Task
+    if err := json.Unmarshal(patchedJSON, &task); err != nil {
+        http.Error(w, decodeError(err), http.StatusBadRequest)
+        return
+    }
+
+    if task.ID != id {
+        http.Error(w, "Task ID cannot be changed", http.StatusBadRequest)
+        return
+    }
+
+    h.saveTask(w, r, task)
+}
+
+func (h *TaskHandler) saveTask(w http.ResponseWriter, r *http.Request, task model.Task) {
+    // A task cannot be moved out of the project it is addressed through.
+    if projectID, ok := projectFromContext(r.Context()); ok && (task.ProjectID == nil || *task.ProjectID != projectID) {
+        http.Error(w, "Task project_id does not match URL", http.StatusBadRequest)
+        return
+    }
+    if err := validateTask(task); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    // A change of done is recorded in the task history like the /done and
+    // /undone transitions.
+    updated, err := h.repo.Update(r.Context(), task, actorFromRequest(r))
+    if err != nil {
+        switch {
+        case errors.Is(err, storage.ErrNotFound):
+            http.Error(w, "Task not found", http.StatusNotFound)
+        case errors.Is(err, storage.ErrValidation):
+            http.Error(w, errorMessage(err), http.StatusBadRequest)
+        case errors.Is(err, storage.ErrOpenSubtasks):
+            http.Error(w, "Task has open subtasks; complete them first", http.StatusConflict)
+        case errors.Is(err, storage.ErrBlocked):
+            http.Error(w, "Task is blocked by open tasks; complete them first", http.StatusConflict)
+        default:
+            http.Error(w, "Failed to update task", http.StatusInternalServerError)
+        }
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(updated)
+}
+
+func validateTask(task model.Task) error {
+    title := strings.TrimSpace(task.Title)
+    if title == "" {
+        return fmt.Errorf("Title is required")
+    }
+    if utf8.RuneCountInString(task.Title) > maxTitleLength {
+        return fmt.Errorf("Title must be at most %d characters", maxTitleLength)
+    }
+    if utf8.RuneCountInString(task.Description) > maxDescriptionLength {
+        return fmt.Errorf("Description must be at most %d characters", maxDescriptionLength)
+    }
+    if !task.Priority.Valid() {
+        return fmt.Errorf("Priority must be one of low, normal, high, urgent")
+    }
+    if _, err := model.NormalizeTags(task.Tags); err != nil {
+        return err
+    }
+    if err := validateRecurrence(task); err != nil {
+        return err
+    }
+
+    return nil
+}
+
+// validateRecurrence checks the recurrence rule of a task, which repeats from
+// its due time.
+func validateRecurrence(task model.Task) error {
+    if task.Recurrence == "" {
+        return nil
+    }
+    if _, err := recurrence.Parse(task.Recurrence); err != nil {
+        return fmt.Errorf("Invalid recurrence: %v", err)
+    }
+    if task.DueAt == nil {
+        return fmt.Errorf("Recurring task must have a due date")
+    }
+    return nil
+}
+
+// decodeError describes why a task in the request body could not be decoded.
+func decodeError(err error) string {
+    var priorityErr *model.InvalidPriorityError
+    if errors.As(err, &priorityErr) {
+        return "Invalid input: " + priorityErr.Error()
+    }
+    return "Invalid input"
+}
+
+func actorFromRequest(r *http.Request) string {
+    if actor := strings.TrimSpace(r.Header.Get(actorHeader)); actor != "" {
+        return actor
+    }
+    return "anonymous"
+}
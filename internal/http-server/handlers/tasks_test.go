@@ -0,0 +1,141 @@
+package handlers
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/go-chi/chi/v5"
+
+    "todo-golang/internal/model"
+    "todo-golang/storage"
+)
+
+func newTestRouter(repo storage.TaskRepository) *chi.Mux {
+    r := chi.NewRouter()
+    NewTaskHandler(repo).SetupRoutes(r)
+    return r
+}
+
+func serve(t *testing.T, router http.Handler, method, path, body string, header ...string) *httptest.ResponseRecorder {
+    t.Helper()
+    req := httptest.NewRequest(method, path, strings.NewReader(body))
+    for i := 0; i+1 < len(header); i += 2 {
+        req.Header.Set(header[i], header[i+1])
+    }
+    rec := httptest.NewRecorder()
+    router.ServeHTTP(rec, req)
+    return rec
+}
+
+func TestCreateTaskValidation(t *testing.T) {
+    router := newTestRouter(storage.NewMemoryTaskRepository())
+
+    tests := []struct {
+        name string
+        body string
+        want int
+    }{
+        {"valid", `{"title":"Buy milk"}`, http.StatusCreated},
+        {"missing title", `{"description":"no title"}`, http.StatusBadRequest},
+        {"blank title", `{"title":"   "}`, http.StatusBadRequest},
+        {"long title", `{"title":"` + strings.Repeat("x", maxTitleLength+1) + `"}`, http.StatusBadRequest},
+        {"title at the limit", `{"title":"` + strings.Repeat("я", maxTitleLength) + `"}`, http.StatusCreated},
+        {"long description", `{"title":"x","description":"` + strings.Repeat("x", maxDescriptionLength+1) + `"}`, http.StatusBadRequest},
+        {"invalid tag", `{"title":"x","tags":["two words"]}`, http.StatusBadRequest},
+        {"recurrence without due", `{"title":"x","recurrence":"FREQ=DAILY"}`, http.StatusBadRequest},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            rec := serve(t, router, http.MethodPost, "/tasks", tt.body)
+            if rec.Code != tt.want {
+                t.Errorf("POST /tasks %s = %d %q, want %d", tt.name, rec.Code, rec.Body.String(), tt.want)
+            }
+        })
+    }
+
+    rec := serve(t, router, http.MethodGet, "/tasks", "")
+    if got := strings.Count(rec.Body.String(), `"id":`); got != 2 {
+        t.Errorf("%d tasks stored, want 2 valid ones", got)
+    }
+}
+
+func TestUpdateTaskRecordsStatusChange(t *testing.T) {
+    repo := storage.NewMemoryTaskRepository()
+    router := newTestRouter(repo)
+
+    task, err := repo.Add(context.Background(), model.Task{Title: "Ship it", Priority: model.PriorityNormal})
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    steps := []struct {
+        method, body, actor string
+    }{
+        {http.MethodPatch, `{"done":true}`, "alice"},
+        {http.MethodPatch, `{"title":"Ship it today"}`, "bob"},
+        {http.MethodPut, `{"title":"Ship it today","done":false}`, "carol"},
+    }
+    for _, step := range steps {
+        rec := serve(t, router, step.method, "/tasks/1", step.body, actorHeader, step.actor)
+        if rec.Code != http.StatusOK {
+            t.Fatalf("%s %s = %d %q", step.method, step.body, rec.Code, rec.Body.String())
+        }
+    }
+
+    rec := serve(t, router, http.MethodGet, "/tasks/1/history", "")
+    var events []model.TaskEvent
+    if err := json.NewDecoder(rec.Body).Decode(&events); err != nil {
+        t.Fatal(err)
+    }
+    if len(events) != 2 ||
+        events[0].TaskID != task.ID || events[0].Action != model.TaskEventDone || events[0].Actor != "alice" ||
+        events[1].Action != model.TaskEventReopened || events[1].Actor != "carol" {
+        t.Errorf("history = %+v, want done by alice and reopened by carol", events)
+    }
+}
+
+func TestUpdateTaskKeepsProject(t *testing.T) {
+    ctx := context.Background()
+    repo := storage.NewMemoryTaskRepository()
+    router := chi.NewRouter()
+    NewProjectHandler(repo, NewTaskHandler(repo)).SetupRoutes(router)
+
+    for _, name := range []string{"Work", "Home"} {
+        if _, err := repo.AddProject(ctx, model.Project{Name: name, DeletePolicy: model.ProjectDeleteBlock}); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if rec := serve(t, router, http.MethodPost, "/projects/1/tasks", `{"title":"Ship it"}`); rec.Code != http.StatusCreated {
+        t.Fatalf("POST /projects/1/tasks = %d %q", rec.Code, rec.Body.String())
+    }
+
+    tests := []struct {
+        method, body string
+        want         int
+    }{
+        {http.MethodPut, `{"title":"Ship it","project_id":2}`, http.StatusBadRequest},
+        {http.MethodPatch, `{"project_id":2}`, http.StatusBadRequest},
+        {http.MethodPatch, `{"project_id":null}`, http.StatusBadRequest},
+        {http.MethodPut, `{"title":"Ship it today"}`, http.StatusOK},
+        {http.MethodPatch, `{"title":"Ship it now"}`, http.StatusOK},
+    }
+    for _, tt := range tests {
+        rec := serve(t, router, tt.method, "/projects/1/tasks/1", tt.body)
+        if rec.Code != tt.want {
+            t.Errorf("%s %s = %d %q, want %d", tt.method, tt.body, rec.Code, rec.Body.String(), tt.want)
+        }
+    }
+
+    task, err := repo.GetByID(ctx, 1)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if task.ProjectID == nil || *task.ProjectID != 1 || task.Title != "Ship it now" {
+        t.Errorf("task = %+v, want it in project 1 titled %q", task, "Ship it now")
+    }
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+    "encoding/json"
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/go-chi/chi/v5"
+
+    "todo-golang/internal/filter"
+    "todo-golang/internal/model"
+    "todo-golang/storage"
+)
+
+// defaultTrashSort puts the most recently deleted tasks first.
+var defaultTrashSort = filter.MustParseSort("-deleted_at")
+
+// GetTrash
+// @Summary Получить корзину
+// @Description Возвращает удаленные задачи постранично; поддерживает те же параметры фильтрации и сортировки, что и GET /tasks. По умолчанию первыми идут задачи, удаленные последними
+// @Tags tasks
+// @Produce json
+// @Param q query string false "Выражение фильтра, например: deleted>2026-01-01"
+// @Param tz query string false "Часовой пояс IANA для дат без времени в q, например Europe/Moscow (по умолчанию UTC)"
+// @Param sort query string false "Сортировка (по умолчанию -deleted_at)"
+// @Param limit query int false "Размер страницы (1-500, по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param render query string false "Формат описаний: markdown (по умолчанию) или html"
+// @Success 200 {object} TaskPage "Страница списка удаленных задач"
+// @Header 200 {string} Link "Ссылка на следующую страницу (rel=next)"
+// @Failure 400 {object} map[string]string "Некорректные параметры запроса"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /trash [get]
+func (h *TaskHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+    expr, err := parseFilterQuery(r)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    h.listTasks(w, r, defaultTrashSort, func(page storage.Page) ([]model.Task, error) {
+        return h.repo.Trash(r.Context(), expr, page)
+    })
+}
+
+// RestoreTask
+// @Summary Восстановить задачу из корзины
+// @Description Восстанавливает удаленную задачу вместе с подзадачами, удаленными вместе с ней. Задачу нельзя восстановить, пока в корзине находится ее родительская задача
+// @Tags tasks
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 200 {object} model.Task "Восстановленная задача"
+// @Failure 400 {object} map[string]string "Некорректный идентификатор задачи"
+// @Failure 404 {object} map[string]string "Задача не найдена в корзине"
+// @Failure 409 {object} map[string]string "Родительская задача находится в корзине"
+// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Router /tasks/{id}/restore [post]
+func (h *TaskHandler) RestoreTask(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid task ID", http.StatusBadRequest)
+        return
+    }
+
+    // requireTaskInProject cannot see tasks in the trash, so the project of a
+    // scoped request is checked here.
+    if projectID, ok := projectFromContext(r.Context()); ok {
+        expr := filter.AllOf(filter.Equal("id", id), filter.Equal("project_id", projectID))
+        tasks, err := h.repo.Trash(r.Context(), expr, storage.Page{Limit: 1})
+        if err != nil {
+            http.Error(w, "Failed to fetch task", http.StatusInternalServerError)
+            return
+        }
+        if len(tasks) == 0 {
+            http.Error(w, "Task not found", http.StatusNotFound)
+            return
+        }
+    }
+
+    if err := h.repo.Restore(r.Context(), id); err != nil {
+        switch {
+        case errors.Is(err, storage.ErrNotFound):
+            http.Error(w, "Task not found", http.StatusNotFound)
+        case errors.Is(err, storage.ErrParentDeleted):
+            http.Error(w, "Parent task is deleted", http.StatusConflict)
+        default:
+            http.Error(w, "Failed to restore task", http.StatusInternalServerError)
+        }
+        return
+    }
+
+    task, err := h.repo.GetByID(r.Context(), id)
+    if err != nil {
+        http.Error(w, "Failed to fetch task", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(task)
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+    "encoding/json"
+    "fmt"
+    "strings"
+)
+
+// Priority is stored as a small integer so that it sorts naturally; in JSON it
+// is written by name.
+type Priority int16
+
+const (
+    PriorityLow    Priority = 1
+    PriorityNormal Priority = 2
+    PriorityHigh   Priority = 3
+    PriorityUrgent Priority = 4
+)
+
+var priorityNames = map[Priority]string{
+    PriorityLow:    "low",
+    PriorityNormal: "normal",
+    PriorityHigh:   "high",
+    PriorityUrgent: "urgent",
+}
+
+// InvalidPriorityError reports a priority name that is not one of the known levels.
+type InvalidPriorityError struct {
+    Name string
+}
+
+func (e *InvalidPriorityError) Error() string {
+    return fmt.Sprintf("unknown priority %q: must be one of low, normal, high, urgent", e.Name)
+}
+
+// ParsePriority converts a case-insensitive priority name.
+func ParsePriority(name string) (Priority, error) {
+    for p, n := range priorityNames {
+        if strings.EqualFold(name, n) {
+            return p, nil
+        }
+    }
+    return 0, &InvalidPriorityError{Name: name}
+}
+
+func (p Priority) Valid() bool {
+    _, ok := priorityNames[p]
+    return ok
+}
+
+func (p Priority) String() string {
+    if name, ok := priorityNames[p]; ok {
+        return name
+    }
+    return fmt.Sprintf("Priority(%d)", int16(p))
+}
+
+func (p Priority) MarshalJSON() ([]byte, error) {
+    if !p.Valid() {
+        return nil, fmt.Errorf("invalid priority %d", int16(p))
+    }
+    return json.Marshal(p.String())
+}
+
+func (p *Priority) UnmarshalJSON(data []byte) error {
+    var name string
+    if err := json.Unmarshal(data, &name); err != nil {
+        return &InvalidPriorityError{Name: string(data)}
+    }
+
+    parsed, err := ParsePriority(name)
+    if err != nil {
+        return err
+    }
+    *p = parsed
+    return nil
+}
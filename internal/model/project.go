@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Delete policies of a project: what happens to its tasks when it is deleted.
+const (
+    ProjectDeleteBlock   = "block"
+    ProjectDeleteCascade = "cascade"
+)
+
+// Project groups tasks. Tasks refer to it through Task.ProjectID.
+type Project struct {
+    ID   int    `json:"id"`
+    Name string `json:"name"`
+    // DeletePolicy is "block" to refuse deleting a project that still has
+    // tasks, or "cascade" to move the tasks to the trash with it.
+    DeletePolicy string    `json:"delete_policy" enums:"block,cascade"`
+    CreatedAt    time.Time `json:"created_at"`
+    UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+// MaxTagLength matches the VARCHAR(64) name column of tags.
+const MaxTagLength = 64
+
+// TagCount is a tag together with the number of tasks labelled with it.
+type TagCount struct {
+    Name  string `json:"name"`
+    Count int    `json:"count"`
+}
+
+// NormalizeTag trims and lower-cases a tag. Tags may not contain spaces or
+// commas, so that they can be listed in query parameters and filter expressions.
+func NormalizeTag(tag string) (string, error) {
+    tag = strings.ToLower(strings.TrimSpace(tag))
+    if tag == "" {
+        return "", fmt.Errorf("Tag must not be empty")
+    }
+    if utf8.RuneCountInString(tag) > MaxTagLength {
+        return "", fmt.Errorf("Tag must be at most %d characters", MaxTagLength)
+    }
+    if strings.ContainsFunc(tag, func(r rune) bool { return unicode.IsSpace(r) || r == ',' }) {
+        return "", fmt.Errorf("Tag %q must not contain spaces or commas", tag)
+    }
+    return tag, nil
+}
+
+// NormalizeTags normalizes every tag and returns them sorted without duplicates.
+func NormalizeTags(tags []string) ([]string, error) {
+    normalized := make([]string, 0, len(tags))
+    for _, tag := range tags {
+        tag, err := NormalizeTag(tag)
+        if err != nil {
+            return nil, err
+        }
+        normalized = append(normalized, tag)
+    }
+
+    sort.Strings(normalized)
+    return compactTags(normalized), nil
+}
+
+func compactTags(sorted []string) []string {
+    result := sorted[:0]
+    for i, tag := range sorted {
+        if i == 0 || tag != sorted[i-1] {
+            result = append(result, tag)
+        }
+    }
+    return result
+}
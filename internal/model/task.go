@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+type Task struct {
+    ID          int        `json:"id"`
+    Title       string     `json:"title"`
+    // Description is a long-form Markdown body.
+    Description string     `json:"description"`
+    Done        bool       `json:"done"`
+    // ProjectID is nil for tasks that belong to no project.
+    ProjectID   *int       `json:"project_id"`
+    // ParentID is set for subtasks.
+    ParentID    *int       `json:"parent_id"`
+    Priority    Priority   `json:"priority" swaggertype:"string" enums:"low,normal,high,urgent"`
+    // Tags are lower-case and sorted.
+    Tags        []string   `json:"tags"`
+    // BlockedBy lists the tasks that must be done before this one. It is
+    // maintained through the blockers endpoints and ignored on input.
+    BlockedBy   []int      `json:"blocked_by"`
+    // Blocked is set on open tasks with at least one open blocker.
+    Blocked     bool       `json:"blocked"`
+    // DueAt is an absolute instant; clients may send it with any UTC offset.
+    DueAt       *time.Time `json:"due_at"`
+    // Recurrence is an RFC 5545 RRULE, e.g. "FREQ=WEEKLY;BYDAY=MO", repeating
+    // the task from its due time, optionally with a TZID part naming the time
+    // zone it is computed in. It is empty for one-off tasks.
+    Recurrence  string     `json:"recurrence"`
+    CreatedAt   time.Time  `json:"created_at"`
+    UpdatedAt   time.Time  `json:"updated_at"`
+    CompletedAt *time.Time `json:"completed_at"`
+    // DeletedAt is set while the task is in the trash.
+    DeletedAt   *time.Time `json:"deleted_at"`
+}
+
+// TaskTree is a task together with its subtasks, to arbitrary depth.
+type TaskTree struct {
+    Task
+    Subtasks []TaskTree `json:"subtasks"`
+}
+
+// Occurrence is an instance of a recurring task.
+type Occurrence struct {
+    DueAt time.Time `json:"due_at"`
+}
+
+const (
+    TaskEventDone     = "done"
+    TaskEventReopened = "reopened"
+)
+
+// TaskEvent is an entry in a task's history, recording who changed its status and when.
+type TaskEvent struct {
+    ID        int       `json:"id"`
+    TaskID    int       `json:"task_id"`
+    Action    string    `json:"action"`
+    Actor     string    `json:"actor"`
+    CreatedAt time.Time `json:"created_at"`
+}
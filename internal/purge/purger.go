@@ -0,0 +1,56 @@
+package purge
+
+import (
+    "context"
+    "log"
+    "time"
+
+    "todo-golang/storage"
+)
+
+// Purger periodically removes for good the tasks that have stayed in the
+// trash longer than the retention period.
+type Purger struct {
+    repo      storage.TaskRepository
+    retention time.Duration
+    interval  time.Duration
+}
+
+func NewPurger(repo storage.TaskRepository, retention, interval time.Duration) *Purger {
+    return &Purger{repo: repo, retention: retention, interval: interval}
+}
+
+// Run purges the trash once at start and then every interval until ctx is
+// cancelled.
+func (p *Purger) Run(ctx context.Context) {
+    p.purge(ctx, time.Now().UTC())
+
+    ticker := time.NewTicker(p.interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case t := <-ticker.C:
+            p.purge(ctx, t.UTC())
+        }
+    }
+}
+
+// purge removes the tasks deleted before now minus the retention period.
+// Failures are logged and retried on the next tick, except those caused by
+// ctx being cancelled on shutdown.
+func (p *Purger) purge(ctx context.Context, now time.Time) {
+    n, err := p.repo.Purge(ctx, now.Add(-p.retention))
+    if err != nil && ctx.Err() != nil {
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to purge trash: %v", err)
+        return
+    }
+    if n > 0 {
+        log.Printf("Purged %d tasks from trash", n)
+    }
+}
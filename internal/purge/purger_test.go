@@ -0,0 +1,65 @@
+package purge
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "todo-golang/internal/model"
+    "todo-golang/storage"
+)
+
+func TestPurge(t *testing.T) {
+    ctx := context.Background()
+    repo := storage.NewMemoryTaskRepository()
+
+    // deleted adds a task, moves it to the trash and returns it as trashed.
+    deleted := func(title string) model.Task {
+        task, err := repo.Add(ctx, model.Task{Title: title, Priority: model.PriorityNormal})
+        if err != nil {
+            t.Fatal(err)
+        }
+        if err := repo.Delete(ctx, task.ID); err != nil {
+            t.Fatal(err)
+        }
+        trash, err := repo.Trash(ctx, nil, storage.Page{})
+        if err != nil {
+            t.Fatal(err)
+        }
+        for _, trashed := range trash {
+            if trashed.ID == task.ID {
+                return trashed
+            }
+        }
+        t.Fatalf("task %d is not in the trash", task.ID)
+        return model.Task{}
+    }
+
+    expired := deleted("Expired")
+    time.Sleep(time.Millisecond)
+    recent := deleted("Recent")
+    live, err := repo.Add(ctx, model.Task{Title: "Live", Priority: model.PriorityNormal})
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    // The recent task has been in the trash for exactly the retention period.
+    retention := 24 * time.Hour
+    p := NewPurger(repo, retention, time.Hour)
+    p.purge(ctx, recent.DeletedAt.Add(retention))
+
+    trash, err := repo.Trash(ctx, nil, storage.Page{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(trash) != 1 || trash[0].ID != recent.ID {
+        t.Errorf("trash after purge = %+v, want only task %d", trash, recent.ID)
+    }
+    if _, err := repo.GetByID(ctx, expired.ID); !errors.Is(err, storage.ErrNotFound) {
+        t.Errorf("GetByID(expired) error = %v, want ErrNotFound", err)
+    }
+    if _, err := repo.GetByID(ctx, live.ID); err != nil {
+        t.Errorf("GetByID(live): %v", err)
+    }
+}
@@ -0,0 +1,446 @@
+// Package recurrence implements the subset of RFC 5545 recurrence rules
+// (RRULE) used by recurring tasks, e.g. `FREQ=WEEKLY;BYDAY=MO,TH`.
+//
+// Supported parts are FREQ (DAILY, WEEKLY, MONTHLY or YEARLY), INTERVAL,
+// COUNT, UNTIL, BYDAY (weekdays without ordinals; not with YEARLY),
+// BYMONTHDAY (MONTHLY only) and WKST. RFC 5545 attaches the time zone to
+// DTSTART; here it is given by the extra part TZID, e.g. TZID=Europe/Berlin.
+// Occurrences are computed in that zone, or in UTC without TZID, and keep the
+// start's wall-clock time across daylight saving changes.
+package recurrence
+
+import (
+    "fmt"
+    "slices"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+type Freq string
+
+const (
+    Daily   Freq = "DAILY"
+    Weekly  Freq = "WEEKLY"
+    Monthly Freq = "MONTHLY"
+    Yearly  Freq = "YEARLY"
+)
+
+// maxPeriods bounds how many periods in a row may have no occurrence, so that
+// the search ends for rules matching no date, such as BYMONTHDAY=31 every 12
+// months from February.
+const maxPeriods = 10000
+
+const (
+    untilLayout     = "20060102T150405Z"
+    untilDateLayout = "20060102"
+)
+
+var weekdays = map[string]time.Weekday{
+    "SU": time.Sunday,
+    "MO": time.Monday,
+    "TU": time.Tuesday,
+    "WE": time.Wednesday,
+    "TH": time.Thursday,
+    "FR": time.Friday,
+    "SA": time.Saturday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Rule is a parsed recurrence rule. The first occurrence of a series is its
+// start (DTSTART), which is supplied separately.
+type Rule struct {
+    Freq     Freq
+    Interval int
+    // Count limits the number of occurrences, including the start; 0 means no limit.
+    Count int
+    // Until is the last instant an occurrence may fall on.
+    Until      *time.Time
+    ByDay      []time.Weekday
+    ByMonthDay []int
+    WeekStart  time.Weekday
+    // Location is the time zone of TZID, nil for UTC.
+    Location *time.Location
+}
+
+// Parse reads a rule such as `FREQ=MONTHLY;BYMONTHDAY=1,-1;COUNT=12`. An
+// "RRULE:" prefix is allowed.
+func Parse(s string) (*Rule, error) {
+    s = strings.TrimSpace(s)
+    if len(s) >= 6 && strings.EqualFold(s[:6], "RRULE:") {
+        s = s[6:]
+    }
+
+    r := &Rule{Interval: 1, WeekStart: time.Monday}
+    seen := make(map[string]bool)
+    var until string
+
+    for _, part := range strings.Split(s, ";") {
+        name, raw, ok := strings.Cut(part, "=")
+        name = strings.ToUpper(strings.TrimSpace(name))
+        raw = strings.TrimSpace(raw)
+        value := strings.ToUpper(raw)
+        if !ok || name == "" || value == "" {
+            return nil, fmt.Errorf("expected NAME=VALUE, got %q", part)
+        }
+        if seen[name] {
+            return nil, fmt.Errorf("%s is given more than once", name)
+        }
+        seen[name] = true
+
+        var err error
+        switch name {
+        case "FREQ":
+            r.Freq = Freq(value)
+            if r.Freq != Daily && r.Freq != Weekly && r.Freq != Monthly && r.Freq != Yearly {
+                err = fmt.Errorf("FREQ must be DAILY, WEEKLY, MONTHLY or YEARLY")
+            }
+        case "INTERVAL":
+            r.Interval, err = parsePositive(name, value)
+        case "COUNT":
+            r.Count, err = parsePositive(name, value)
+        case "UNTIL":
+            // A date is read in the rule's time zone, which may come later.
+            until = value
+        case "BYDAY":
+            r.ByDay, err = parseWeekdays(value)
+        case "BYMONTHDAY":
+            r.ByMonthDay, err = parseMonthDays(value)
+        case "WKST":
+            day, ok := weekdays[value]
+            if !ok {
+                err = fmt.Errorf("WKST must be a weekday such as MO")
+            }
+            r.WeekStart = day
+        case "TZID":
+            r.Location, err = parseLocation(raw)
+        default:
+            err = fmt.Errorf("%s is not supported", name)
+        }
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    if until != "" {
+        var err error
+        if r.Until, err = parseUntil(until, r.location()); err != nil {
+            return nil, err
+        }
+    }
+
+    switch {
+    case r.Freq == "":
+        return nil, fmt.Errorf("FREQ is required")
+    case r.Count > 0 && r.Until != nil:
+        return nil, fmt.Errorf("COUNT and UNTIL cannot be combined")
+    case len(r.ByDay) > 0 && r.Freq == Yearly:
+        return nil, fmt.Errorf("BYDAY is not supported with FREQ=YEARLY")
+    case len(r.ByMonthDay) > 0 && r.Freq != Monthly:
+        return nil, fmt.Errorf("BYMONTHDAY is only supported with FREQ=MONTHLY")
+    }
+
+    return r, nil
+}
+
+func parsePositive(name, value string) (int, error) {
+    n, err := strconv.Atoi(value)
+    if err != nil || n < 1 {
+        return 0, fmt.Errorf("%s must be a positive integer", name)
+    }
+    return n, nil
+}
+
+// parseUntil accepts a UTC timestamp or a date, which includes the whole day
+// in loc.
+func parseUntil(value string, loc *time.Location) (*time.Time, error) {
+    if t, err := time.Parse(untilLayout, value); err == nil {
+        return &t, nil
+    }
+
+    t, err := time.ParseInLocation(untilDateLayout, value, loc)
+    if err != nil {
+        return nil, fmt.Errorf("UNTIL must be a date (20060102) or UTC timestamp (20060102T150405Z)")
+    }
+    t = t.AddDate(0, 0, 1).Add(-time.Second).UTC()
+    return &t, nil
+}
+
+// parseLocation loads an IANA time zone. Local is refused, as it depends on
+// the machine the rule is evaluated on.
+func parseLocation(value string) (*time.Location, error) {
+    loc, err := time.LoadLocation(value)
+    if err != nil || value == "Local" {
+        return nil, fmt.Errorf("TZID must be an IANA time zone such as Europe/Berlin")
+    }
+    return loc, nil
+}
+
+func parseWeekdays(value string) ([]time.Weekday, error) {
+    var days []time.Weekday
+    for _, name := range strings.Split(value, ",") {
+        day, ok := weekdays[name]
+        if !ok {
+            return nil, fmt.Errorf("BYDAY must list weekdays such as MO,WE; ordinals are not supported")
+        }
+        if !slices.Contains(days, day) {
+            days = append(days, day)
+        }
+    }
+    return days, nil
+}
+
+func parseMonthDays(value string) ([]int, error) {
+    var days []int
+    for _, s := range strings.Split(value, ",") {
+        day, err := strconv.Atoi(s)
+        if err != nil || day == 0 || day < -31 || day > 31 {
+            return nil, fmt.Errorf("BYMONTHDAY must list days from 1 to 31 or -31 to -1")
+        }
+        if !slices.Contains(days, day) {
+            days = append(days, day)
+        }
+    }
+    return days, nil
+}
+
+// String formats the rule in its canonical form, which Parse accepts.
+func (r *Rule) String() string {
+    parts := []string{"FREQ=" + string(r.Freq)}
+    if r.Interval > 1 {
+        parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+    }
+    if r.Count > 0 {
+        parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+    }
+    if r.Until != nil {
+        parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+    }
+    if len(r.ByDay) > 0 {
+        names := make([]string, len(r.ByDay))
+        for i, day := range r.ByDay {
+            names[i] = weekdayNames[day]
+        }
+        parts = append(parts, "BYDAY="+strings.Join(names, ","))
+    }
+    if len(r.ByMonthDay) > 0 {
+        days := make([]string, len(r.ByMonthDay))
+        for i, day := range r.ByMonthDay {
+            days[i] = strconv.Itoa(day)
+        }
+        parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+    }
+    if r.WeekStart != time.Monday {
+        parts = append(parts, "WKST="+weekdayNames[r.WeekStart])
+    }
+    if r.Location != nil {
+        parts = append(parts, "TZID="+r.Location.String())
+    }
+    return strings.Join(parts, ";")
+}
+
+// location returns the time zone the occurrences are computed in.
+func (r *Rule) location() *time.Location {
+    if r.Location == nil {
+        return time.UTC
+    }
+    return r.Location
+}
+
+// Between returns the occurrences of the series starting at dtstart that fall
+// within [from, to], at most limit of them. Occurrences are returned in UTC.
+func (r *Rule) Between(dtstart, from, to time.Time, limit int) []time.Time {
+    var occurrences []time.Time
+    r.iterate(dtstart, from, func(t time.Time) bool {
+        if t.After(to) {
+            return false
+        }
+        if !t.Before(from) {
+            occurrences = append(occurrences, t)
+        }
+        return len(occurrences) < limit
+    })
+    return occurrences
+}
+
+// Next returns the first occurrence of the series starting at dtstart that
+// is later than both dtstart and after, with the rule for the rest of the
+// series from that occurrence on; its COUNT is reduced by the occurrences
+// passed over. ok is false if the series ends before then.
+func (r *Rule) Next(dtstart, after time.Time) (next time.Time, rest *Rule, ok bool) {
+    index := 0
+    r.iterate(dtstart, after, func(t time.Time) bool {
+        if t.After(dtstart) && t.After(after) {
+            next, ok = t, true
+            return false
+        }
+        index++
+        return true
+    })
+    if !ok {
+        return time.Time{}, nil, false
+    }
+
+    rest = &Rule{}
+    *rest = *r
+    if r.Count > 0 {
+        rest.Count = r.Count - index
+    }
+    return next, rest, true
+}
+
+// iterate calls yield with the occurrences of the series starting at dtstart
+// in order, in UTC, until yield returns false or the series ends. The start
+// itself is always the first occurrence, as in RFC 5545, even if the rule does
+// not match it. Unless COUNT needs them counted, the occurrences in periods
+// before the one containing from are skipped.
+func (r *Rule) iterate(dtstart, from time.Time, yield func(time.Time) bool) {
+    dtstart = dtstart.In(r.location())
+    count := 0
+
+    emit := func(t time.Time) bool {
+        if r.Until != nil && t.After(*r.Until) {
+            return false
+        }
+        count++
+        return yield(t.UTC()) && (r.Count == 0 || count < r.Count)
+    }
+
+    if !emit(dtstart) {
+        return
+    }
+
+    period := 0
+    if r.Count == 0 {
+        period = r.period(dtstart, from)
+    }
+    for empty := 0; empty < maxPeriods; period++ {
+        candidates := r.candidates(dtstart, period)
+        if len(candidates) == 0 {
+            empty++
+            continue
+        }
+        empty = 0
+        for _, t := range candidates {
+            if t.After(dtstart) && !emit(t) {
+                return
+            }
+        }
+    }
+}
+
+// period returns the number of the period containing t, counted as in
+// candidates, or 0 if t is not after dtstart.
+func (r *Rule) period(dtstart, t time.Time) int {
+    t = t.In(dtstart.Location())
+    if !t.After(dtstart) {
+        return 0
+    }
+
+    // day numbers a date, so that the difference of two is the number of
+    // days between them.
+    day := func(t time.Time) int {
+        year, month, date := t.Date()
+        return int(time.Date(year, month, date, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60))
+    }
+
+    var n int
+    switch r.Freq {
+    case Daily:
+        n = day(t) - day(dtstart)
+    case Weekly:
+        weekStart := day(dtstart) - (int(dtstart.Weekday())-int(r.WeekStart)+7)%7
+        n = (day(t) - weekStart) / 7
+    case Monthly:
+        n = 12*(t.Year()-dtstart.Year()) + int(t.Month()-dtstart.Month())
+    case Yearly:
+        n = t.Year() - dtstart.Year()
+    }
+    return n / r.Interval
+}
+
+// candidates returns the dates the rule matches in the given period after
+// the one containing dtstart, in order, at dtstart's wall-clock time in its
+// location.
+func (r *Rule) candidates(dtstart time.Time, period int) []time.Time {
+    loc := dtstart.Location()
+    year, month, day := dtstart.Date()
+    midnight := time.Date(year, month, day, 0, 0, 0, 0, loc)
+    hour, min, sec := dtstart.Clock()
+    at := func(day time.Time) time.Time {
+        year, month, date := day.Date()
+        return time.Date(year, month, date, hour, min, sec, dtstart.Nanosecond(), loc)
+    }
+    step := period * r.Interval
+
+    switch r.Freq {
+    case Daily:
+        day := midnight.AddDate(0, 0, step)
+        if len(r.ByDay) > 0 && !slices.Contains(r.ByDay, day.Weekday()) {
+            return nil
+        }
+        return []time.Time{at(day)}
+
+    case Weekly:
+        offset := (int(midnight.Weekday()) - int(r.WeekStart) + 7) % 7
+        weekStart := midnight.AddDate(0, 0, 7*step-offset)
+        days := r.ByDay
+        if len(days) == 0 {
+            days = []time.Weekday{dtstart.Weekday()}
+        }
+
+        var dates []time.Time
+        for i := 0; i < 7; i++ {
+            day := weekStart.AddDate(0, 0, i)
+            if slices.Contains(days, day.Weekday()) {
+                dates = append(dates, at(day))
+            }
+        }
+        return dates
+
+    case Monthly:
+        first := time.Date(midnight.Year(), midnight.Month()+time.Month(step), 1, 0, 0, 0, 0, loc)
+        length := first.AddDate(0, 1, -1).Day()
+
+        var days []int
+        switch {
+        case len(r.ByMonthDay) > 0:
+            for _, day := range r.ByMonthDay {
+                if day < 0 {
+                    day += length + 1
+                }
+                if day >= 1 && day <= length && !slices.Contains(days, day) {
+                    days = append(days, day)
+                }
+            }
+            sort.Ints(days)
+        case len(r.ByDay) > 0:
+            for day := 1; day <= length; day++ {
+                days = append(days, day)
+            }
+        case dtstart.Day() <= length:
+            days = []int{dtstart.Day()}
+        }
+
+        var dates []time.Time
+        for _, day := range days {
+            date := first.AddDate(0, 0, day-1)
+            if len(r.ByDay) == 0 || slices.Contains(r.ByDay, date.Weekday()) {
+                dates = append(dates, at(date))
+            }
+        }
+        return dates
+
+    case Yearly:
+        date := time.Date(midnight.Year()+step, midnight.Month(), midnight.Day(), 0, 0, 0, 0, loc)
+        // February 29 only occurs in leap years.
+        if date.Day() != midnight.Day() {
+            return nil
+        }
+        return []time.Time{at(date)}
+    }
+
+    return nil
+}
@@ -0,0 +1,345 @@
+package recurrence
+
+import (
+    "strings"
+    "testing"
+    "time"
+    _ "time/tzdata"
+)
+
+func mustTime(t *testing.T, s string) time.Time {
+    t.Helper()
+    tm, err := time.Parse(time.RFC3339, s)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return tm
+}
+
+func formatTimes(times []time.Time) string {
+    s := make([]string, len(times))
+    for i, t := range times {
+        s[i] = t.Format(time.RFC3339)
+    }
+    return strings.Join(s, " ")
+}
+
+func TestParse(t *testing.T) {
+    tests := []struct {
+        input string
+        want  string
+    }{
+        {`FREQ=DAILY`, `FREQ=DAILY`},
+        {`RRULE:freq=weekly;byday=mo,th,mo`, `FREQ=WEEKLY;BYDAY=MO,TH`},
+        {`FREQ=WEEKLY;INTERVAL=1;WKST=MO`, `FREQ=WEEKLY`},
+        {`FREQ=WEEKLY;INTERVAL=2;WKST=SU`, `FREQ=WEEKLY;INTERVAL=2;WKST=SU`},
+        {`FREQ=MONTHLY;BYMONTHDAY=1,-1;COUNT=12`, `FREQ=MONTHLY;COUNT=12;BYMONTHDAY=1,-1`},
+        {`FREQ=DAILY;UNTIL=20260131T120000Z`, `FREQ=DAILY;UNTIL=20260131T120000Z`},
+        {`FREQ=DAILY;UNTIL=20260131`, `FREQ=DAILY;UNTIL=20260131T235959Z`},
+        // TZID keeps its case and also applies to an UNTIL date given before it.
+        {`FREQ=WEEKLY;BYDAY=MO;TZID=Europe/Moscow`, `FREQ=WEEKLY;BYDAY=MO;TZID=Europe/Moscow`},
+        {`FREQ=DAILY;UNTIL=20260131;TZID=Europe/Moscow`, `FREQ=DAILY;UNTIL=20260131T205959Z;TZID=Europe/Moscow`},
+        {`FREQ=DAILY;TZID=UTC`, `FREQ=DAILY;TZID=UTC`},
+    }
+
+    for _, tt := range tests {
+        rule, err := Parse(tt.input)
+        if err != nil {
+            t.Errorf("Parse(%q): %v", tt.input, err)
+            continue
+        }
+        if got := rule.String(); got != tt.want {
+            t.Errorf("Parse(%q).String() = %s, want %s", tt.input, got, tt.want)
+        }
+        if again, err := Parse(rule.String()); err != nil || again.String() != tt.want {
+            t.Errorf("Parse(%q) does not round-trip: %v, %v", rule.String(), again, err)
+        }
+    }
+}
+
+func TestParseErrors(t *testing.T) {
+    tests := []struct {
+        input string
+        msg   string
+    }{
+        {``, `expected NAME=VALUE`},
+        {`FREQ`, `expected NAME=VALUE`},
+        {`INTERVAL=2`, `FREQ is required`},
+        {`FREQ=HOURLY`, `FREQ must be`},
+        {`FREQ=DAILY;FREQ=WEEKLY`, `FREQ is given more than once`},
+        {`FREQ=DAILY;INTERVAL=0`, `INTERVAL must be a positive integer`},
+        {`FREQ=DAILY;COUNT=x`, `COUNT must be a positive integer`},
+        {`FREQ=DAILY;UNTIL=2026-01-31`, `UNTIL must be`},
+        {`FREQ=DAILY;COUNT=3;UNTIL=20260131`, `COUNT and UNTIL cannot be combined`},
+        {`FREQ=WEEKLY;BYDAY=1MO`, `ordinals are not supported`},
+        {`FREQ=YEARLY;BYDAY=MO`, `BYDAY is not supported with FREQ=YEARLY`},
+        {`FREQ=WEEKLY;BYMONTHDAY=1`, `BYMONTHDAY is only supported with FREQ=MONTHLY`},
+        {`FREQ=MONTHLY;BYMONTHDAY=32`, `BYMONTHDAY must list days`},
+        {`FREQ=MONTHLY;BYMONTHDAY=0`, `BYMONTHDAY must list days`},
+        {`FREQ=WEEKLY;WKST=XX`, `WKST must be a weekday`},
+        {`FREQ=DAILY;BYHOUR=9`, `BYHOUR is not supported`},
+        {`FREQ=DAILY;TZID=Mars/Olympus`, `TZID must be an IANA time zone`},
+        {`FREQ=DAILY;TZID=Local`, `TZID must be an IANA time zone`},
+    }
+
+    for _, tt := range tests {
+        if _, err := Parse(tt.input); err == nil || !strings.Contains(err.Error(), tt.msg) {
+            t.Errorf("Parse(%q) error = %v, want %q", tt.input, err, tt.msg)
+        }
+    }
+}
+
+func TestBetween(t *testing.T) {
+    tests := []struct {
+        name     string
+        rule     string
+        dtstart  string
+        from, to string
+        want     string
+    }{
+        {
+            name: "daily", rule: `FREQ=DAILY`, dtstart: "2026-01-01T09:00:00Z",
+            from: "2026-01-01T00:00:00Z", to: "2026-01-04T09:00:00Z",
+            want: "2026-01-01T09:00:00Z 2026-01-02T09:00:00Z 2026-01-03T09:00:00Z 2026-01-04T09:00:00Z",
+        },
+        {
+            name: "daily window", rule: `FREQ=DAILY`, dtstart: "2026-01-01T09:00:00Z",
+            from: "2026-01-02T10:00:00Z", to: "2026-01-04T08:59:59Z",
+            want: "2026-01-03T09:00:00Z",
+        },
+        {
+            name: "interval", rule: `FREQ=DAILY;INTERVAL=3`, dtstart: "2026-01-01T09:00:00Z",
+            from: "2026-01-01T00:00:00Z", to: "2026-01-10T00:00:00Z",
+            want: "2026-01-01T09:00:00Z 2026-01-04T09:00:00Z 2026-01-07T09:00:00Z",
+        },
+        {
+            name: "far past interval", rule: `FREQ=DAILY;INTERVAL=3`, dtstart: "1960-01-01T09:00:00Z",
+            from: "2026-01-01T00:00:00Z", to: "2026-01-10T00:00:00Z",
+            want: "2026-01-02T09:00:00Z 2026-01-05T09:00:00Z 2026-01-08T09:00:00Z",
+        },
+        {
+            // The start is an occurrence even though it is a Thursday.
+            name: "daily byday", rule: `FREQ=DAILY;BYDAY=MO,FR`, dtstart: "2026-01-01T09:00:00Z",
+            from: "2026-01-01T00:00:00Z", to: "2026-01-10T00:00:00Z",
+            want: "2026-01-01T09:00:00Z 2026-01-02T09:00:00Z 2026-01-05T09:00:00Z 2026-01-09T09:00:00Z",
+        },
+        {
+            name: "weekly", rule: `FREQ=WEEKLY`, dtstart: "2026-01-01T09:00:00Z",
+            from: "2026-01-01T00:00:00Z", to: "2026-01-20T00:00:00Z",
+            want: "2026-01-01T09:00:00Z 2026-01-08T09:00:00Z 2026-01-15T09:00:00Z",
+        },
+        {
+            name: "weekly interval byday", rule: `FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH`, dtstart: "2026-01-01T09:00:00Z",
+            from: "2026-01-01T00:00:00Z", to: "2026-01-31T00:00:00Z",
+            want: "2026-01-01T09:00:00Z 2026-01-12T09:00:00Z 2026-01-15T09:00:00Z 2026-01-26T09:00:00Z 2026-01-29T09:00:00Z",
+        },
+        {
+            // RFC 5545 section 3.8.5.3: WKST changes which weeks are skipped.
+            name: "wkst monday", rule: `FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=MO`, dtstart: "1997-08-05T09:00:00Z",
+            from: "1997-08-01T00:00:00Z", to: "1997-12-31T00:00:00Z",
+            want: "1997-08-05T09:00:00Z 1997-08-10T09:00:00Z 1997-08-19T09:00:00Z 1997-08-24T09:00:00Z",
+        },
+        {
+            name: "wkst sunday", rule: `FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU`, dtstart: "1997-08-05T09:00:00Z",
+            from: "1997-08-01T00:00:00Z", to: "1997-12-31T00:00:00Z",
+            want: "1997-08-05T09:00:00Z 1997-08-17T09:00:00Z 1997-08-19T09:00:00Z 1997-08-31T09:00:00Z",
+        },
+        {
+            // Months without a 31st are skipped.
+            name: "monthly", rule: `FREQ=MONTHLY`, dtstart: "2026-01-31T09:00:00Z",
+            from: "2026-01-01T00:00:00Z", to: "2026-06-01T00:00:00Z",
+            want: "2026-01-31T09:00:00Z 2026-03-31T09:00:00Z 2026-05-31T09:00:00Z",
+        },
+        {
+            name: "bymonthday", rule: `FREQ=MONTHLY;BYMONTHDAY=1,-1`, dtstart: "2026-01-01T09:00:00Z",
+            from: "2026-01-01T00:00:00Z", to: "2026-03-01T09:00:00Z",
+            want: "2026-01-01T09:00:00Z 2026-01-31T09:00:00Z 2026-02-01T09:00:00Z 2026-02-28T09:00:00Z 2026-03-01T09:00:00Z",
+        },
+        {
+            name: "bymonthday 31", rule: `FREQ=MONTHLY;BYMONTHDAY=31`, dtstart: "2026-01-31T09:00:00Z",
+            from: "2026-01-01T00:00:00Z", to: "2026-06-01T00:00:00Z",
+            want: "2026-01-31T09:00:00Z 2026-03-31T09:00:00Z 2026-05-31T09:00:00Z",
+        },
+        {
+            name: "monthly byday", rule: `FREQ=MONTHLY;BYDAY=MO`, dtstart: "2026-01-05T09:00:00Z",
+            from: "2026-01-01T00:00:00Z", to: "2026-02-03T00:00:00Z",
+            want: "2026-01-05T09:00:00Z 2026-01-12T09:00:00Z 2026-01-19T09:00:00Z 2026-01-26T09:00:00Z 2026-02-02T09:00:00Z",
+        },
+        {
+            name: "yearly leap day", rule: `FREQ=YEARLY`, dtstart: "2024-02-29T09:00:00Z",
+            from: "2024-01-01T00:00:00Z", to: "2032-12-31T00:00:00Z",
+            want: "2024-02-29T09:00:00Z 2028-02-29T09:00:00Z 2032-02-29T09:00:00Z",
+        },
+        {
+            name: "count", rule: `FREQ=DAILY;COUNT=3`, dtstart: "2026-01-01T09:00:00Z",
+            from: "2026-01-01T00:00:00Z", to: "2026-12-31T00:00:00Z",
+            want: "2026-01-01T09:00:00Z 2026-01-02T09:00:00Z 2026-01-03T09:00:00Z",
+        },
+        {
+            // COUNT includes occurrences before from.
+            name: "count window", rule: `FREQ=DAILY;COUNT=3`, dtstart: "2026-01-01T09:00:00Z",
+            from: "2026-01-02T10:00:00Z", to: "2026-12-31T00:00:00Z",
+            want: "2026-01-03T09:00:00Z",
+        },
+        {
+            name: "until date", rule: `FREQ=DAILY;UNTIL=20260103`, dtstart: "2026-01-01T09:00:00Z",
+            from: "2026-01-01T00:00:00Z", to: "2026-12-31T00:00:00Z",
+            want: "2026-01-01T09:00:00Z 2026-01-02T09:00:00Z 2026-01-03T09:00:00Z",
+        },
+        {
+            name: "until timestamp", rule: `FREQ=DAILY;UNTIL=20260103T080000Z`, dtstart: "2026-01-01T09:00:00Z",
+            from: "2026-01-01T00:00:00Z", to: "2026-12-31T00:00:00Z",
+            want: "2026-01-01T09:00:00Z 2026-01-02T09:00:00Z",
+        },
+        {
+            // Monday 01:00 in Moscow is Sunday 22:00 UTC.
+            name: "byday in tzid", rule: `FREQ=WEEKLY;BYDAY=MO;TZID=Europe/Moscow`, dtstart: "2026-01-04T22:00:00Z",
+            from: "2026-01-01T00:00:00Z", to: "2026-01-19T00:00:00Z",
+            want: "2026-01-04T22:00:00Z 2026-01-11T22:00:00Z 2026-01-18T22:00:00Z",
+        },
+        {
+            name: "byday in utc", rule: `FREQ=WEEKLY;BYDAY=MO`, dtstart: "2026-01-04T22:00:00Z",
+            from: "2026-01-01T00:00:00Z", to: "2026-01-19T00:00:00Z",
+            want: "2026-01-04T22:00:00Z 2026-01-05T22:00:00Z 2026-01-12T22:00:00Z",
+        },
+        {
+            // Berlin switches to summer time on 2026-03-29; the task stays at 09:00.
+            name: "daily across dst", rule: `FREQ=DAILY;TZID=Europe/Berlin`, dtstart: "2026-03-28T08:00:00Z",
+            from: "2026-03-01T00:00:00Z", to: "2026-03-31T00:00:00Z",
+            want: "2026-03-28T08:00:00Z 2026-03-29T07:00:00Z 2026-03-30T07:00:00Z",
+        },
+        {
+            name: "weekly across dst end", rule: `FREQ=WEEKLY;BYDAY=MO;TZID=America/New_York`, dtstart: "2026-10-26T13:30:00Z",
+            from: "2026-10-01T00:00:00Z", to: "2026-11-10T00:00:00Z",
+            want: "2026-10-26T13:30:00Z 2026-11-02T14:30:00Z 2026-11-09T14:30:00Z",
+        },
+        {
+            name: "bymonthday in tzid", rule: `FREQ=MONTHLY;BYMONTHDAY=1;TZID=Asia/Tokyo`, dtstart: "2026-01-31T15:00:00Z",
+            from: "2026-01-01T00:00:00Z", to: "2026-04-01T00:00:00Z",
+            want: "2026-01-31T15:00:00Z 2026-02-28T15:00:00Z 2026-03-31T15:00:00Z",
+        },
+        {
+            // The UNTIL date ends at midnight in New York, 05:00 UTC.
+            name: "until date in tzid", rule: `FREQ=DAILY;UNTIL=20260102;TZID=America/New_York`, dtstart: "2026-01-01T04:30:00Z",
+            from: "2026-01-01T00:00:00Z", to: "2026-12-31T00:00:00Z",
+            want: "2026-01-01T04:30:00Z 2026-01-02T04:30:00Z 2026-01-03T04:30:00Z",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            rule, err := Parse(tt.rule)
+            if err != nil {
+                t.Fatal(err)
+            }
+            got := rule.Between(mustTime(t, tt.dtstart), mustTime(t, tt.from), mustTime(t, tt.to), 100)
+            if formatTimes(got) != tt.want {
+                t.Errorf("Between = %s\nwant      %s", formatTimes(got), tt.want)
+            }
+        })
+    }
+}
+
+func TestBetweenLimit(t *testing.T) {
+    rule, err := Parse(`FREQ=DAILY`)
+    if err != nil {
+        t.Fatal(err)
+    }
+    start := mustTime(t, "2026-01-01T09:00:00Z")
+    if got := rule.Between(start, start, start.AddDate(1, 0, 0), 5); len(got) != 5 {
+        t.Errorf("Between returned %d occurrences, want 5", len(got))
+    }
+}
+
+func TestNext(t *testing.T) {
+    tests := []struct {
+        name    string
+        rule    string
+        dtstart string
+        after   string
+        want    string
+        rest    string
+    }{
+        {
+            name: "after the start", rule: `FREQ=DAILY`, dtstart: "2026-01-01T09:00:00Z", after: "2025-12-01T00:00:00Z",
+            want: "2026-01-02T09:00:00Z", rest: `FREQ=DAILY`,
+        },
+        {
+            // Occurrences missed while overdue are skipped and counted.
+            name: "skips missed", rule: `FREQ=DAILY;COUNT=5`, dtstart: "2026-01-01T09:00:00Z", after: "2026-01-03T12:00:00Z",
+            want: "2026-01-04T09:00:00Z", rest: `FREQ=DAILY;COUNT=2`,
+        },
+        {
+            name: "at an occurrence", rule: `FREQ=WEEKLY;BYDAY=MO,TH`, dtstart: "2026-01-01T09:00:00Z", after: "2026-01-05T09:00:00Z",
+            want: "2026-01-08T09:00:00Z", rest: `FREQ=WEEKLY;BYDAY=MO,TH`,
+        },
+        {
+            name: "last of count", rule: `FREQ=DAILY;COUNT=2`, dtstart: "2026-01-01T09:00:00Z", after: "2026-01-01T10:00:00Z",
+            want: "2026-01-02T09:00:00Z", rest: `FREQ=DAILY;COUNT=1`,
+        },
+        {
+            name: "count exhausted", rule: `FREQ=DAILY;COUNT=2`, dtstart: "2026-01-01T09:00:00Z", after: "2026-01-02T09:00:00Z",
+        },
+        {
+            name: "past until", rule: `FREQ=DAILY;UNTIL=20260103`, dtstart: "2026-01-01T09:00:00Z", after: "2026-01-03T10:00:00Z",
+        },
+        {
+            name: "until kept", rule: `FREQ=DAILY;UNTIL=20260103`, dtstart: "2026-01-01T09:00:00Z", after: "2026-01-01T10:00:00Z",
+            want: "2026-01-02T09:00:00Z", rest: `FREQ=DAILY;UNTIL=20260103T235959Z`,
+        },
+        {
+            name: "tzid across dst", rule: `FREQ=WEEKLY;TZID=Europe/Berlin`, dtstart: "2026-03-23T08:00:00Z", after: "2026-03-24T00:00:00Z",
+            want: "2026-03-30T07:00:00Z", rest: `FREQ=WEEKLY;TZID=Europe/Berlin`,
+        },
+
+        // Series started decades ago.
+        {
+            name: "far past daily", rule: `FREQ=DAILY`, dtstart: "1990-01-01T09:00:00Z", after: "2026-10-18T10:00:00Z",
+            want: "2026-10-19T09:00:00Z", rest: `FREQ=DAILY`,
+        },
+        {
+            name: "far past daily tzid", rule: `FREQ=DAILY;TZID=Europe/Berlin`, dtstart: "1980-01-01T08:00:00Z", after: "2026-07-01T12:00:00Z",
+            want: "2026-07-02T07:00:00Z", rest: `FREQ=DAILY;TZID=Europe/Berlin`,
+        },
+        {
+            name: "far past weekly", rule: `FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,FR;WKST=SU`, dtstart: "1970-01-06T09:00:00Z", after: "2026-10-18T10:00:00Z",
+            want: "2026-10-27T09:00:00Z", rest: `FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,FR;WKST=SU`,
+        },
+        {
+            name: "far past monthly", rule: `FREQ=MONTHLY;INTERVAL=5;BYMONTHDAY=-1`, dtstart: "1900-01-31T09:00:00Z", after: "2026-10-18T10:00:00Z",
+            want: "2027-02-28T09:00:00Z", rest: `FREQ=MONTHLY;INTERVAL=5;BYMONTHDAY=-1`,
+        },
+        {
+            name: "far past yearly", rule: `FREQ=YEARLY`, dtstart: "1904-02-29T09:00:00Z", after: "2026-10-18T10:00:00Z",
+            want: "2028-02-29T09:00:00Z", rest: `FREQ=YEARLY`,
+        },
+        {
+            // With COUNT every occurrence is counted from the start.
+            name: "far past count", rule: `FREQ=DAILY;COUNT=20000`, dtstart: "1990-01-01T09:00:00Z", after: "2026-10-18T10:00:00Z",
+            want: "2026-10-19T09:00:00Z", rest: `FREQ=DAILY;COUNT=6560`,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            rule, err := Parse(tt.rule)
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            next, rest, ok := rule.Next(mustTime(t, tt.dtstart), mustTime(t, tt.after))
+            if tt.want == "" {
+                if ok {
+                    t.Errorf("Next = %s, %s; want the end of the series", next.Format(time.RFC3339), rest)
+                }
+                return
+            }
+            if !ok {
+                t.Fatalf("Next: series ended, want %s", tt.want)
+            }
+            if got := next.Format(time.RFC3339); got != tt.want || rest.String() != tt.rest {
+                t.Errorf("Next = %s, %s; want %s, %s", got, rest, tt.want, tt.rest)
+            }
+        })
+    }
+}
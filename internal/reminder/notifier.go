@@ -0,0 +1,70 @@
+// Package reminder watches task deadlines and sends a reminder through a
+// Notifier when an open task reaches its due time.
+package reminder
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "log"
+    "net/http"
+    "time"
+
+    "todo-golang/internal/model"
+)
+
+// Reminder is emitted once for each open task whose due time has passed.
+type Reminder struct {
+    Task model.Task `json:"task"`
+    // SentAt is when the scheduler noticed the deadline.
+    SentAt time.Time `json:"sent_at"`
+}
+
+// Notifier delivers reminders. Implementations must be safe for use by a
+// single goroutine; the scheduler never calls Notify concurrently.
+type Notifier interface {
+    Notify(ctx context.Context, reminder Reminder) error
+}
+
+// LogNotifier writes reminders to the standard logger.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, reminder Reminder) error {
+    log.Printf("Task %d %q is due (due at %s)", reminder.Task.ID, reminder.Task.Title, reminder.Task.DueAt.Format(time.RFC3339))
+    return nil
+}
+
+// WebhookNotifier posts each reminder as JSON to URL.
+type WebhookNotifier struct {
+    URL    string
+    Client *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+    return &WebhookNotifier{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, reminder Reminder) error {
+    body, err := json.Marshal(reminder)
+    if err != nil {
+        return fmt.Errorf("failed to encode reminder: %w", err)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+    if err != nil {
+        return fmt.Errorf("failed to build webhook request: %w", err)
+    }
+    req.Header.Set("Content-Type", "application/json")
+
+    resp, err := n.Client.Do(req)
+    if err != nil {
+        return fmt.Errorf("webhook request failed: %w", err)
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode >= 300 {
+        return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+    }
+    return nil
+}
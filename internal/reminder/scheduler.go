@@ -0,0 +1,83 @@
+package reminder
+
+import (
+    "context"
+    "log/slog"
+    "time"
+
+    "todo-golang/internal/filter"
+    "todo-golang/storage"
+)
+
+const batchSize = 100
+
+var dueSort = filter.MustParseSort("due_at")
+
+// Scheduler periodically looks for open tasks whose due time passed since the
+// previous check and sends a reminder for each of them.
+//
+// Only deadlines passed while the scheduler runs are reported, so a restart
+// neither repeats nor backfills reminders. Every replica running a scheduler
+// sends its own reminders.
+type Scheduler struct {
+    repo     storage.TaskRepository
+    notifier Notifier
+    interval time.Duration
+    last     time.Time
+}
+
+func NewScheduler(repo storage.TaskRepository, notifier Notifier, interval time.Duration) *Scheduler {
+    return &Scheduler{repo: repo, notifier: notifier, interval: interval}
+}
+
+// Run checks for due tasks every interval until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+    s.last = time.Now().UTC()
+
+    ticker := time.NewTicker(s.interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case t := <-ticker.C:
+            s.check(ctx, t.UTC())
+        }
+    }
+}
+
+// check notifies about open tasks due in (s.last, now]. If the tasks cannot be
+// listed, the window is kept and retried on the next tick.
+func (s *Scheduler) check(ctx context.Context, now time.Time) {
+    expr := filter.AllOf(
+        filter.Equal("done", false),
+        filter.NewCond("due_at", filter.OpGt, s.last),
+        filter.NewCond("due_at", filter.OpLe, now),
+    )
+    page := storage.Page{Limit: batchSize, Sort: dueSort}
+
+    for {
+        tasks, err := s.repo.GetFiltered(ctx, expr, page)
+        if err != nil && ctx.Err() != nil {
+            return
+        }
+        if err != nil {
+            slog.Error("Failed to check due tasks", "err", err)
+            return
+        }
+
+        for _, task := range tasks {
+            if err := s.notifier.Notify(ctx, Reminder{Task: task, SentAt: now}); err != nil {
+                slog.Error("Failed to send reminder", "task", task.ID, "err", err)
+            }
+        }
+
+        if len(tasks) < page.Limit {
+            break
+        }
+        page.After = filter.SortValues(tasks[len(tasks)-1], page.Order())
+    }
+
+    s.last = now
+}
@@ -0,0 +1,109 @@
+package reminder
+
+import (
+    "context"
+    "errors"
+    "slices"
+    "testing"
+    "time"
+
+    "todo-golang/internal/filter"
+    "todo-golang/internal/model"
+    "todo-golang/storage"
+)
+
+// failingRepository fails GetFiltered while fail is set.
+type failingRepository struct {
+    storage.TaskRepository
+    fail bool
+}
+
+func (r *failingRepository) GetFiltered(ctx context.Context, expr filter.Expr, page storage.Page) ([]model.Task, error) {
+    if r.fail {
+        return nil, errors.New("database is down")
+    }
+    return r.TaskRepository.GetFiltered(ctx, expr, page)
+}
+
+// recordingNotifier collects the IDs of the tasks it is notified about.
+type recordingNotifier struct {
+    ids []int
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, reminder Reminder) error {
+    n.ids = append(n.ids, reminder.Task.ID)
+    return nil
+}
+
+func TestSchedulerCheck(t *testing.T) {
+    ctx := context.Background()
+    start := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+    at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+    memory := storage.NewMemoryTaskRepository()
+    add := func(title string, due time.Time, done bool) int {
+        task, err := memory.Add(ctx, model.Task{Title: title, Priority: model.PriorityNormal, DueAt: &due, Done: done})
+        if err != nil {
+            t.Fatal(err)
+        }
+        return task.ID
+    }
+    add("before start", at(0), false)
+    first := add("first tick", at(1), false)
+    add("done", at(1), true)
+    second := add("after the failure", at(2), false)
+    third := add("on the third tick", at(3), false)
+    add("later", at(10), false)
+
+    repo := &failingRepository{TaskRepository: memory}
+    notifier := &recordingNotifier{}
+    s := NewScheduler(repo, notifier, time.Minute)
+    s.last = at(0)
+
+    steps := []struct {
+        now  time.Time
+        fail bool
+        want []int
+    }{
+        // The deadline at the start of the window was reported before.
+        {now: at(1), want: []int{first}},
+        // A failed tick reports nothing and keeps (1, 2] open.
+        {now: at(2), fail: true},
+        {now: at(3), want: []int{second, third}},
+        // Nothing is reported twice.
+        {now: at(4)},
+        {now: at(4)},
+    }
+    for i, step := range steps {
+        notifier.ids = nil
+        repo.fail = step.fail
+        s.check(ctx, step.now)
+        if !slices.Equal(notifier.ids, step.want) {
+            t.Errorf("tick %d at %s reported %v, want %v", i+1, step.now.Format(time.TimeOnly), notifier.ids, step.want)
+        }
+    }
+}
+
+func TestSchedulerCheckPagesThroughDueTasks(t *testing.T) {
+    ctx := context.Background()
+    start := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+
+    repo := storage.NewMemoryTaskRepository()
+    var want []int
+    for i := 0; i < batchSize+batchSize/2; i++ {
+        due := start.Add(time.Duration(i+1) * time.Second)
+        task, err := repo.Add(ctx, model.Task{Title: "Due", Priority: model.PriorityNormal, DueAt: &due})
+        if err != nil {
+            t.Fatal(err)
+        }
+        want = append(want, task.ID)
+    }
+
+    notifier := &recordingNotifier{}
+    s := NewScheduler(repo, notifier, time.Minute)
+    s.last = start
+    s.check(ctx, start.Add(time.Hour))
+    if !slices.Equal(notifier.ids, want) {
+        t.Errorf("reported %d tasks, want each of the %d due ones once", len(notifier.ids), len(want))
+    }
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+    "fmt"
+    "slices"
+    "strings"
+
+    "todo-golang/internal/model"
+)
+
+// blockersCTE is a WITH clause naming the IDs of all tasks that task $1
+// depends on, directly or through other tasks.
+const blockersCTE = `
+    WITH RECURSIVE blockers (id) AS (
+        SELECT blocker_id FROM task_dependencies WHERE task_id = $1
+        UNION
+        SELECT d.blocker_id FROM task_dependencies d JOIN blockers b ON d.task_id = b.id
+    )`
+
+// Statements shared by the SQL repositories for maintaining task dependencies.
+const (
+    dependsOnQuery     = blockersCTE + ` SELECT EXISTS (SELECT 1 FROM blockers WHERE id = $2)`
+    linkBlockerQuery   = `INSERT INTO task_dependencies (task_id, blocker_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+    unlinkBlockerQuery = `DELETE FROM task_dependencies WHERE task_id = $1 AND blocker_id = $2`
+    // openBlockersQuery selects the open blockers of task $1 if it is open itself.
+    openBlockersQuery  = `
+    SELECT d.blocker_id FROM task_dependencies d
+    JOIN tasks t ON t.id = d.task_id
+    JOIN tasks b ON b.id = d.blocker_id
+    WHERE d.task_id = $1 AND t.done = FALSE AND b.done = FALSE AND b.deleted_at IS NULL
+    ORDER BY d.blocker_id`
+)
+
+// blockersQuery returns the query selecting the blockers of tasks, except those
+// in the trash, with their status, and an index of the tasks by ID. Every
+// task's blocker list is reset to an empty list.
+func blockersQuery(tasks []model.Task) (string, []interface{}, map[int]*model.Task) {
+    byID := make(map[int]*model.Task, len(tasks))
+    placeholders := make([]string, len(tasks))
+    args := make([]interface{}, len(tasks))
+
+    for i := range tasks {
+        tasks[i].BlockedBy = []int{}
+        tasks[i].Blocked = false
+        byID[tasks[i].ID] = &tasks[i]
+        placeholders[i] = fmt.Sprintf("$%d", i+1)
+        args[i] = tasks[i].ID
+    }
+
+    query := `
+    SELECT d.task_id, d.blocker_id, b.done FROM task_dependencies d
+    JOIN tasks b ON b.id = d.blocker_id AND b.deleted_at IS NULL
+    WHERE d.task_id IN (` + strings.Join(placeholders, ", ") + `)
+    ORDER BY d.blocker_id`
+
+    return query, args, byID
+}
+
+// addBlocker records on a task that blockerID blocks it. Only open tasks are
+// reported as blocked.
+func addBlocker(task *model.Task, blockerID int, blockerDone bool) {
+    task.BlockedBy = append(task.BlockedBy, blockerID)
+    if !task.Done && !blockerDone {
+        task.Blocked = true
+    }
+}
+
+// checkUnblocked returns an error if any of the tasks being completed has an
+// open blocker that is not completed along with it. openBlockers returns the
+// open blockers of an open task.
+func checkUnblocked(ids []int, openBlockers func(id int) ([]int, error)) error {
+    for _, id := range ids {
+        blockers, err := openBlockers(id)
+        if err != nil {
+            return fmt.Errorf("failed to check blockers: %w", err)
+        }
+        for _, blockerID := range blockers {
+            if !slices.Contains(ids, blockerID) {
+                return ErrBlocked
+            }
+        }
+    }
+    return nil
+}
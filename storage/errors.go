@@ -0,0 +1,53 @@
+package storage
+
+import "errors"
+
+// Kinds of errors caused by the request rather than by a failure of the
+// backend. Every such error returned by a repository wraps one of them, so
+// callers can tell them apart with errors.Is.
+var (
+    // ErrNotFound means that the task or project addressed does not exist.
+    ErrNotFound = errors.New("not found")
+    // ErrConflict means that the change is not allowed in the current state
+    // of the data.
+    ErrConflict = errors.New("conflict")
+    // ErrValidation means that the data passed in is invalid.
+    ErrValidation = errors.New("validation failed")
+)
+
+var (
+    ErrTaskNotFound    = &kindError{ErrNotFound, "task not found"}
+    ErrProjectNotFound = &kindError{ErrNotFound, "project not found"}
+    ErrBlockerNotFound = &kindError{ErrNotFound, "blocker task not found"}
+
+    // ErrUnknownProject and ErrUnknownParent reject tasks referring to a
+    // project or a parent task that does not exist.
+    ErrUnknownProject  = &kindError{ErrValidation, "project not found"}
+    ErrUnknownParent   = &kindError{ErrValidation, "parent task not found"}
+    ErrOwnParent       = &kindError{ErrValidation, "task cannot be its own parent"}
+    ErrParentIsSubtask = &kindError{ErrValidation, "parent task is a subtask of this task"}
+    ErrSelfBlocker     = &kindError{ErrValidation, "task cannot block itself"}
+
+    ErrOpenSubtasks    = &kindError{ErrConflict, "task has open subtasks"}
+    ErrBlocked         = &kindError{ErrConflict, "task is blocked"}
+    ErrDependencyCycle = &kindError{ErrConflict, "dependency would create a cycle"}
+    ErrParentDeleted   = &kindError{ErrConflict, "parent task is deleted"}
+    ErrProjectHasTasks = &kindError{ErrConflict, "project has tasks"}
+)
+
+// kindError is an error of one of the kinds above, with a message that can
+// be shown to clients.
+type kindError struct {
+    kind error
+    msg  string
+}
+
+func (e *kindError) Error() string { return e.msg }
+
+func (e *kindError) Unwrap() error { return e.kind }
+
+// invalid marks err, such as a tag rejected by model.NormalizeTag, as an
+// ErrValidation error.
+func invalid(err error) error {
+    return &kindError{ErrValidation, err.Error()}
+}
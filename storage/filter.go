@@ -0,0 +1,71 @@
+package storage
+
+import (
+    "fmt"
+    "strings"
+
+    "todo-golang/internal/filter"
+)
+
+const (
+    dialectPostgres = "postgres"
+    dialectSQLite   = "sqlite"
+)
+
+var sqlOps = map[filter.Op]string{
+    filter.OpEq: "=",
+    filter.OpNe: "<>",
+    filter.OpGt: ">",
+    filter.OpGe: ">=",
+    filter.OpLt: "<",
+    filter.OpLe: "<=",
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// compileFilter translates a filter expression into a parameterised SQL
+// condition, appending its arguments to args.
+func compileFilter(e filter.Expr, dialect string, args *[]interface{}) string {
+    switch e := e.(type) {
+    case *filter.And:
+        return "(" + compileFilter(e.Left, dialect, args) + " AND " + compileFilter(e.Right, dialect, args) + ")"
+    case *filter.Or:
+        return "(" + compileFilter(e.Left, dialect, args) + " OR " + compileFilter(e.Right, dialect, args) + ")"
+    case *filter.Not:
+        return "NOT " + compileFilter(e.X, dialect, args)
+    case *filter.Cond:
+        cond := compileCond(e, dialect, args)
+        if e.Field.Nullable {
+            // Keep the condition two-valued so that NOT behaves like filter.Match,
+            // where a NULL field never matches.
+            return fmt.Sprintf("(%s IS NOT NULL AND %s)", e.Field.Column, cond)
+        }
+        return cond
+    default:
+        panic(fmt.Sprintf("storage: unexpected filter expression %T", e))
+    }
+}
+
+func compileCond(e *filter.Cond, dialect string, args *[]interface{}) string {
+    if e.Field.Kind == filter.KindTags {
+        *args = append(*args, e.Value)
+        exists := fmt.Sprintf("EXISTS (SELECT 1 FROM task_tags tt JOIN tags tg ON tg.id = tt.tag_id WHERE tt.task_id = tasks.id AND tg.name = $%d)", len(*args))
+        if e.Op == filter.OpNe {
+            return "NOT " + exists
+        }
+        return exists
+    }
+
+    if e.Op == filter.OpContains {
+        // SQLite's LIKE is already case-insensitive for ASCII; PostgreSQL needs ILIKE.
+        like := "LIKE"
+        if dialect == dialectPostgres {
+            like = "ILIKE"
+        }
+        *args = append(*args, "%"+likeEscaper.Replace(e.Value.(string))+"%")
+        return fmt.Sprintf(`%s %s $%d ESCAPE '\'`, e.Field.Column, like, len(*args))
+    }
+
+    *args = append(*args, e.Value)
+    return fmt.Sprintf("%s %s $%d", e.Field.Column, sqlOps[e.Op], len(*args))
+}
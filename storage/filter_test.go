@@ -0,0 +1,95 @@
+package storage
+
+import (
+    "context"
+    "slices"
+    "testing"
+    "time"
+
+    "todo-golang/internal/filter"
+    "todo-golang/internal/model"
+)
+
+// TestCompileFilterMatchesMatch checks that the SQL translation of filter
+// expressions selects the same tasks as filter.Match, which the memory
+// repository uses, in particular for NULL values under NOT.
+func TestCompileFilterMatchesMatch(t *testing.T) {
+    ctx := context.Background()
+    r := newTestSQLiteRepository(t)
+
+    project, err := r.AddProject(ctx, model.Project{Name: "Work", DeletePolicy: model.ProjectDeleteBlock})
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    due := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+    later := due.Add(48 * time.Hour)
+    parent, err := r.Add(ctx, model.Task{Title: "Release", Priority: model.PriorityHigh, DueAt: &later, ProjectID: &project.ID})
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, task := range []model.Task{
+        {Title: "Deploy the API", Priority: model.PriorityUrgent, DueAt: &due, ProjectID: &project.ID, ParentID: &parent.ID},
+        {Title: "Write docs", Description: "deploy guide", Priority: model.PriorityNormal, ParentID: &parent.ID},
+        {Title: "Idle", Priority: model.PriorityLow},
+        {Title: "Plan", Priority: model.PriorityNormal, DueAt: &due},
+    } {
+        added, err := r.Add(ctx, task)
+        if err != nil {
+            t.Fatal(err)
+        }
+        // One task without a due date gets a completion time.
+        if added.Title == "Idle" {
+            if err := r.MarkDone(ctx, added.ID, "test", DoneOptions{}); err != nil {
+                t.Fatal(err)
+            }
+        }
+    }
+
+    all, err := r.GetAll(ctx, Page{})
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    for _, q := range []string{
+        `due<2030-01-02`,
+        `-due<2030-01-02`,
+        `due!=2030-01-01T09:00:00Z`,
+        `NOT due!=2030-01-01T09:00:00Z`,
+        `due>=2030-01-01 due<=2030-01-03`,
+        `-(due>=2030-01-01 due<=2030-01-03)`,
+        `project:1`,
+        `-project:1`,
+        `project!=1`,
+        `-project!=1`,
+        `parent:1 OR project:1`,
+        `-(parent:1 OR project:1)`,
+        `-parent>0 -project>0`,
+        `completed>2000-01-01`,
+        `-completed>2000-01-01`,
+        `NOT NOT completed>2000-01-01`,
+        `done:true OR -due>2030-01-02`,
+        `priority>=high -due<2030-01-02`,
+        `text~DEPLOY -project:1`,
+    } {
+        e, err := filter.Parse(q)
+        if err != nil {
+            t.Fatalf("Parse(%q): %v", q, err)
+        }
+
+        var want []int
+        for _, task := range all {
+            if filter.Match(e, task) {
+                want = append(want, task.ID)
+            }
+        }
+
+        tasks, err := r.GetFiltered(ctx, e, Page{})
+        if err != nil {
+            t.Fatalf("GetFiltered(%q): %v", q, err)
+        }
+        if got := taskIDs(tasks); !slices.Equal(got, want) {
+            t.Errorf("GetFiltered(%q) = %v, Match selects %v", q, got, want)
+        }
+    }
+}
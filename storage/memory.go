@@ -0,0 +1,672 @@
+package storage
+
+import (
+    "context"
+    "log"
+    "slices"
+    "sort"
+    "sync"
+    "time"
+
+    "todo-golang/internal/filter"
+    "todo-golang/internal/model"
+)
+
+// MemoryTaskRepository keeps tasks in process memory. Its methods never wait
+// on I/O, so they ignore ctx.
+type MemoryTaskRepository struct {
+    mu          sync.RWMutex
+    tasks       map[int]model.Task
+    nextID      int
+    events      []model.TaskEvent
+    nextEventID int
+    projects      map[int]model.Project
+    nextProjectID int
+    // blockers maps a task ID to the sorted IDs of the tasks blocking it.
+    blockers      map[int][]int
+}
+
+func NewMemoryTaskRepository() *MemoryTaskRepository {
+    return &MemoryTaskRepository{
+        tasks:         make(map[int]model.Task),
+        nextID:        1,
+        nextEventID:   1,
+        projects:      make(map[int]model.Project),
+        nextProjectID: 1,
+        blockers:      make(map[int][]int),
+    }
+}
+
+func (r *MemoryTaskRepository) GetAll(ctx context.Context, page Page) ([]model.Task, error) {
+    return r.GetFiltered(ctx, nil, page)
+}
+
+func (r *MemoryTaskRepository) GetByID(ctx context.Context, id int) (model.Task, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    task, ok := r.live(id)
+    if !ok {
+        return model.Task{}, ErrTaskNotFound
+    }
+
+    return r.withBlockers(task), nil
+}
+
+func (r *MemoryTaskRepository) Add(ctx context.Context, task model.Task) (model.Task, error) {
+    tags, err := model.NormalizeTags(task.Tags)
+    if err != nil {
+        return model.Task{}, invalid(err)
+    }
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if err := r.checkProject(task.ProjectID); err != nil {
+        return model.Task{}, err
+    }
+    if err := r.checkParent(0, task.ParentID); err != nil {
+        return model.Task{}, err
+    }
+
+    task.Tags = tags
+    task = r.insert(task, now())
+
+    log.Println("Task added successfully")
+    return r.withBlockers(task), nil
+}
+
+func (r *MemoryTaskRepository) Update(ctx context.Context, task model.Task, actor string) (model.Task, error) {
+    tags, err := model.NormalizeTags(task.Tags)
+    if err != nil {
+        return model.Task{}, invalid(err)
+    }
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    current, ok := r.live(task.ID)
+    if !ok {
+        return model.Task{}, ErrTaskNotFound
+    }
+    if err := r.checkProject(task.ProjectID); err != nil {
+        return model.Task{}, err
+    }
+    if err := r.checkParent(task.ID, task.ParentID); err != nil {
+        return model.Task{}, err
+    }
+    completing := task.Done && !current.Done
+    if completing {
+        if len(r.openDescendants(task.ID)) > 0 {
+            return model.Task{}, ErrOpenSubtasks
+        }
+        if err := checkUnblocked([]int{task.ID}, r.openBlockers); err != nil {
+            return model.Task{}, err
+        }
+    }
+
+    at := now()
+    task.Tags = tags
+    task.DueAt = utc(task.DueAt)
+    task.CreatedAt = current.CreatedAt
+    task.UpdatedAt = at
+    task.DeletedAt = nil
+    task.CompletedAt = nil
+    if task.Done {
+        task.CompletedAt = current.CompletedAt
+        if task.CompletedAt == nil {
+            task.CompletedAt = &at
+        }
+    }
+    r.tasks[task.ID] = task
+    if task.Done != current.Done {
+        r.recordEvent(task.ID, task.Done, actor, at)
+    }
+    if completing {
+        r.scheduleNext(task, at)
+    }
+
+    log.Println("Task updated successfully")
+    return r.withBlockers(task), nil
+}
+
+func (r *MemoryTaskRepository) Delete(ctx context.Context, id int) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if _, ok := r.live(id); !ok {
+        return ErrTaskNotFound
+    }
+
+    r.trash(id, now())
+
+    log.Println("Task moved to trash")
+    return nil
+}
+
+// trash moves a task and its subtasks to the trash. Callers must hold r.mu
+// for writing.
+func (r *MemoryTaskRepository) trash(id int, at time.Time) {
+    for _, taskID := range append(r.descendants(id), id) {
+        if task, ok := r.live(taskID); ok {
+            task.DeletedAt = &at
+            r.tasks[taskID] = task
+        }
+    }
+}
+
+func (r *MemoryTaskRepository) Trash(ctx context.Context, expr filter.Expr, page Page) ([]model.Task, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    return r.collect(page, func(task model.Task) bool {
+        return task.DeletedAt != nil && filter.Match(expr, task)
+    }), nil
+}
+
+func (r *MemoryTaskRepository) Restore(ctx context.Context, id int) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    task, ok := r.tasks[id]
+    if !ok || task.DeletedAt == nil {
+        return ErrTaskNotFound
+    }
+    if task.ParentID != nil {
+        if _, ok := r.live(*task.ParentID); !ok {
+            return ErrParentDeleted
+        }
+    }
+
+    deletedAt := *task.DeletedAt
+    for _, taskID := range append(r.descendants(id), id) {
+        task := r.tasks[taskID]
+        if task.DeletedAt != nil && task.DeletedAt.Equal(deletedAt) {
+            task.DeletedAt = nil
+            r.tasks[taskID] = task
+        }
+    }
+
+    log.Println("Task restored successfully")
+    return nil
+}
+
+func (r *MemoryTaskRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    purged := 0
+    for _, task := range r.tasks {
+        if task.DeletedAt != nil && task.DeletedAt.Before(before) {
+            purged++
+            r.deleteTask(task.ID)
+        }
+    }
+
+    return purged, nil
+}
+
+func (r *MemoryTaskRepository) MarkDone(ctx context.Context, id int, actor string, opts DoneOptions) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if _, ok := r.live(id); !ok {
+        return ErrTaskNotFound
+    }
+
+    open := r.openDescendants(id)
+    if len(open) > 0 && !opts.Cascade {
+        return ErrOpenSubtasks
+    }
+
+    ids := append(open, id)
+    if !opts.Force {
+        if err := checkUnblocked(ids, r.openBlockers); err != nil {
+            return err
+        }
+    }
+
+    at := now()
+    for _, taskID := range ids {
+        task := r.tasks[taskID]
+        if r.setDone(taskID, true, actor) && !task.Done {
+            r.scheduleNext(task, at)
+        }
+    }
+
+    log.Println("Task marked as done")
+    return nil
+}
+
+func (r *MemoryTaskRepository) Reopen(ctx context.Context, id int, actor string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if !r.setDone(id, false, actor) {
+        return ErrTaskNotFound
+    }
+
+    log.Println("Task reopened")
+    return nil
+}
+
+// insert stores a new task created at the given time. Callers must hold r.mu
+// for writing.
+func (r *MemoryTaskRepository) insert(task model.Task, at time.Time) model.Task {
+    task.ID = r.nextID
+    task.DueAt = utc(task.DueAt)
+    task.CreatedAt = at
+    task.UpdatedAt = at
+    task.CompletedAt = completedAt(task.Done, at)
+    r.nextID++
+    r.tasks[task.ID] = task
+    return task
+}
+
+// scheduleNext creates the next occurrence of a recurring task completed at
+// the given time. Callers must hold r.mu for writing.
+func (r *MemoryTaskRepository) scheduleNext(task model.Task, at time.Time) {
+    next, ok := nextOccurrence(task, at)
+    if !ok {
+        return
+    }
+
+    created := r.insert(next, at)
+    log.Printf("Next occurrence of task %d scheduled as task %d", task.ID, created.ID)
+}
+
+// setDone changes the task status and records the transition in the history.
+// A task that already has the status is left as it is. It reports whether the
+// task exists. Callers must hold r.mu for writing.
+func (r *MemoryTaskRepository) setDone(id int, done bool, actor string) bool {
+    task, ok := r.live(id)
+    if !ok {
+        return false
+    }
+    if task.Done == done {
+        return true
+    }
+
+    at := now()
+    task.Done = done
+    task.UpdatedAt = at
+    if !done {
+        task.CompletedAt = nil
+    } else if task.CompletedAt == nil {
+        task.CompletedAt = &at
+    }
+    r.tasks[id] = task
+    r.recordEvent(id, done, actor, at)
+
+    return true
+}
+
+// recordEvent adds a change of the task status to the history. Callers must
+// hold r.mu for writing.
+func (r *MemoryTaskRepository) recordEvent(id int, done bool, actor string, at time.Time) {
+    r.events = append(r.events, model.TaskEvent{
+        ID:        r.nextEventID,
+        TaskID:    id,
+        Action:    statusAction(done),
+        Actor:     actor,
+        CreatedAt: at,
+    })
+    r.nextEventID++
+}
+
+func (r *MemoryTaskRepository) History(ctx context.Context, id int) ([]model.TaskEvent, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    if _, ok := r.live(id); !ok {
+        return nil, ErrTaskNotFound
+    }
+
+    var events []model.TaskEvent
+    for _, event := range r.events {
+        if event.TaskID == id {
+            events = append(events, event)
+        }
+    }
+
+    return events, nil
+}
+
+func (r *MemoryTaskRepository) GetFiltered(ctx context.Context, expr filter.Expr, page Page) ([]model.Task, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    return r.collect(page, func(task model.Task) bool {
+        return task.DeletedAt == nil && filter.Match(expr, task)
+    }), nil
+}
+
+func (r *MemoryTaskRepository) Subtree(ctx context.Context, id int) (model.TaskTree, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    root, ok := r.live(id)
+    if !ok {
+        return model.TaskTree{}, ErrTaskNotFound
+    }
+
+    var descendants []model.Task
+    for _, taskID := range r.descendants(id) {
+        if task, ok := r.live(taskID); ok {
+            descendants = append(descendants, r.withBlockers(task))
+        }
+    }
+
+    return buildTree(r.withBlockers(root), descendants), nil
+}
+
+func (r *MemoryTaskRepository) AddTag(ctx context.Context, taskID int, tag string) error {
+    tag, err := model.NormalizeTag(tag)
+    if err != nil {
+        return invalid(err)
+    }
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    task, ok := r.live(taskID)
+    if !ok {
+        return ErrTaskNotFound
+    }
+    if slices.Contains(task.Tags, tag) {
+        return nil
+    }
+
+    // Build a new slice: the old one may be shared with tasks already returned.
+    tags := append(slices.Clone(task.Tags), tag)
+    sort.Strings(tags)
+    task.Tags = tags
+    task.UpdatedAt = now()
+    r.tasks[taskID] = task
+
+    log.Println("Task tagged successfully")
+    return nil
+}
+
+func (r *MemoryTaskRepository) RemoveTag(ctx context.Context, taskID int, tag string) error {
+    tag, err := model.NormalizeTag(tag)
+    if err != nil {
+        return invalid(err)
+    }
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    task, ok := r.live(taskID)
+    if !ok {
+        return ErrTaskNotFound
+    }
+    i := slices.Index(task.Tags, tag)
+    if i < 0 {
+        return nil
+    }
+
+    task.Tags = slices.Delete(slices.Clone(task.Tags), i, i+1)
+    task.UpdatedAt = now()
+    r.tasks[taskID] = task
+
+    log.Println("Task untagged successfully")
+    return nil
+}
+
+func (r *MemoryTaskRepository) Tags(ctx context.Context) ([]model.TagCount, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    counts := make(map[string]int)
+    for _, task := range r.tasks {
+        if task.DeletedAt != nil {
+            continue
+        }
+        for _, tag := range task.Tags {
+            counts[tag]++
+        }
+    }
+
+    tags := make([]model.TagCount, 0, len(counts))
+    for name, count := range counts {
+        tags = append(tags, model.TagCount{Name: name, Count: count})
+    }
+    sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+
+    return tags, nil
+}
+
+func (r *MemoryTaskRepository) AddBlocker(ctx context.Context, taskID, blockerID int) error {
+    if taskID == blockerID {
+        return ErrSelfBlocker
+    }
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    task, ok := r.live(taskID)
+    if !ok {
+        return ErrTaskNotFound
+    }
+    if _, ok := r.live(blockerID); !ok {
+        return ErrBlockerNotFound
+    }
+    if slices.Contains(r.blockers[taskID], blockerID) {
+        return nil
+    }
+    if r.dependsOn(blockerID, taskID) {
+        return ErrDependencyCycle
+    }
+
+    blockers := append(r.blockers[taskID], blockerID)
+    sort.Ints(blockers)
+    r.blockers[taskID] = blockers
+    task.UpdatedAt = now()
+    r.tasks[taskID] = task
+
+    log.Println("Task blocker added successfully")
+    return nil
+}
+
+func (r *MemoryTaskRepository) RemoveBlocker(ctx context.Context, taskID, blockerID int) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    task, ok := r.live(taskID)
+    if !ok {
+        return ErrTaskNotFound
+    }
+    i := slices.Index(r.blockers[taskID], blockerID)
+    if i < 0 {
+        return nil
+    }
+
+    r.blockers[taskID] = slices.Delete(r.blockers[taskID], i, i+1)
+    task.UpdatedAt = now()
+    r.tasks[taskID] = task
+
+    log.Println("Task blocker removed successfully")
+    return nil
+}
+
+// collect returns the requested page of tasks matching keep. Callers must hold r.mu.
+func (r *MemoryTaskRepository) collect(page Page, keep func(model.Task) bool) []model.Task {
+    order := page.Order()
+
+    var tasks []model.Task
+    for _, task := range r.tasks {
+        task = r.withBlockers(task)
+        if page.After != nil && filter.CompareSortValues(task, order, page.After) <= 0 {
+            continue
+        }
+        if keep(task) {
+            tasks = append(tasks, task)
+        }
+    }
+
+    sort.Slice(tasks, func(i, j int) bool {
+        return filter.CompareSortValues(tasks[i], order, filter.SortValues(tasks[j], order)) < 0
+    })
+
+    if page.Limit > 0 && len(tasks) > page.Limit {
+        tasks = tasks[:page.Limit]
+    }
+    return tasks
+}
+
+// descendants returns the IDs of all tasks below id in ID order. Callers must hold r.mu.
+func (r *MemoryTaskRepository) descendants(id int) []int {
+    var ids []int
+    queue := []int{id}
+    for len(queue) > 0 {
+        parentID := queue[0]
+        queue = queue[1:]
+        for _, task := range r.tasks {
+            if task.ParentID != nil && *task.ParentID == parentID {
+                ids = append(ids, task.ID)
+                queue = append(queue, task.ID)
+            }
+        }
+    }
+
+    sort.Ints(ids)
+    return ids
+}
+
+// openDescendants returns the IDs of the tasks below id that are not done,
+// leaving out tasks in the trash. Callers must hold r.mu.
+func (r *MemoryTaskRepository) openDescendants(id int) []int {
+    var open []int
+    for _, taskID := range r.descendants(id) {
+        if task, ok := r.live(taskID); ok && !task.Done {
+            open = append(open, taskID)
+        }
+    }
+    return open
+}
+
+// withBlockers returns task with its blockers filled in, except those in the
+// trash. Callers must hold r.mu.
+func (r *MemoryTaskRepository) withBlockers(task model.Task) model.Task {
+    task.BlockedBy = []int{}
+    task.Blocked = false
+    for _, blockerID := range r.blockers[task.ID] {
+        if blocker, ok := r.live(blockerID); ok {
+            addBlocker(&task, blockerID, blocker.Done)
+        }
+    }
+    return task
+}
+
+// openBlockers returns the open blockers of id if it is open itself. Callers
+// must hold r.mu.
+func (r *MemoryTaskRepository) openBlockers(id int) ([]int, error) {
+    if r.tasks[id].Done {
+        return nil, nil
+    }
+
+    var open []int
+    for _, blockerID := range r.blockers[id] {
+        if blocker, ok := r.live(blockerID); ok && !blocker.Done {
+            open = append(open, blockerID)
+        }
+    }
+    return open, nil
+}
+
+// dependsOn reports whether taskID is blocked by blockerID, directly or
+// through other tasks. Callers must hold r.mu.
+func (r *MemoryTaskRepository) dependsOn(taskID, blockerID int) bool {
+    seen := map[int]bool{taskID: true}
+    queue := []int{taskID}
+    for len(queue) > 0 {
+        id := queue[0]
+        queue = queue[1:]
+        for _, b := range r.blockers[id] {
+            if b == blockerID {
+                return true
+            }
+            if !seen[b] {
+                seen[b] = true
+                queue = append(queue, b)
+            }
+        }
+    }
+    return false
+}
+
+// checkParent returns an error if parentID is set but names no task, or if
+// making it the parent of taskID would create a cycle. taskID is 0 for new
+// tasks. Callers must hold r.mu.
+func (r *MemoryTaskRepository) checkParent(taskID int, parentID *int) error {
+    if parentID == nil {
+        return nil
+    }
+    if *parentID == taskID {
+        return ErrOwnParent
+    }
+    if _, ok := r.live(*parentID); !ok {
+        return ErrUnknownParent
+    }
+    if taskID != 0 && slices.Contains(r.descendants(taskID), *parentID) {
+        return ErrParentIsSubtask
+    }
+    return nil
+}
+
+// live returns the task with the given ID unless it is missing or in the
+// trash. Callers must hold r.mu.
+func (r *MemoryTaskRepository) live(id int) (model.Task, bool) {
+    task, ok := r.tasks[id]
+    if !ok || task.DeletedAt != nil {
+        return model.Task{}, false
+    }
+    return task, true
+}
+
+// deleteTask permanently removes a task with its subtasks, their history and
+// their dependencies, mirroring ON DELETE CASCADE. Callers must hold r.mu for
+// writing.
+func (r *MemoryTaskRepository) deleteTask(id int) {
+    for _, taskID := range append(r.descendants(id), id) {
+        delete(r.tasks, taskID)
+        r.dropEvents(taskID)
+        r.dropDependencies(taskID)
+    }
+}
+
+// dropDependencies removes the dependencies of a deleted task and on it,
+// mirroring ON DELETE CASCADE. Callers must hold r.mu for writing.
+func (r *MemoryTaskRepository) dropDependencies(taskID int) {
+    delete(r.blockers, taskID)
+    for id, blockers := range r.blockers {
+        if i := slices.Index(blockers, taskID); i >= 0 {
+            r.blockers[id] = slices.Delete(blockers, i, i+1)
+        }
+    }
+}
+
+// checkProject returns an error if projectID is set but names no project.
+// Callers must hold r.mu.
+func (r *MemoryTaskRepository) checkProject(projectID *int) error {
+    if projectID == nil {
+        return nil
+    }
+    if _, ok := r.projects[*projectID]; !ok {
+        return ErrUnknownProject
+    }
+    return nil
+}
+
+// dropEvents removes the history of a deleted task, mirroring ON DELETE CASCADE.
+// Callers must hold r.mu for writing.
+func (r *MemoryTaskRepository) dropEvents(taskID int) {
+    events := r.events[:0]
+    for _, event := range r.events {
+        if event.TaskID != taskID {
+            events = append(events, event)
+        }
+    }
+    r.events = events
+}
@@ -0,0 +1,260 @@
+package storage
+
+import (
+    "context"
+    "database/sql"
+    "embed"
+    "fmt"
+    "io/fs"
+    "log"
+    "path"
+    "regexp"
+    "sort"
+    "strconv"
+    "time"
+
+    "github.com/jackc/pgx/v5/pgxpool"
+    "github.com/jackc/pgx/v5/stdlib"
+)
+
+//go:embed migrations
+var migrationsFS embed.FS
+
+// migrationLockID is the key of the PostgreSQL advisory lock held while migrating,
+// so that replicas starting at the same time apply each migration exactly once.
+const migrationLockID int64 = 7_402_118_305
+
+var migrationFileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+    Version int
+    Name    string
+    Up      string
+    Down    string
+}
+
+type MigrationStatus struct {
+    Migration
+    Applied   bool
+    AppliedAt time.Time
+}
+
+type Migrator struct {
+    db         *sql.DB
+    dialect    string
+    migrations []Migration
+}
+
+func NewPostgresMigrator(pool *pgxpool.Pool) (*Migrator, error) {
+    return newMigrator(stdlib.OpenDBFromPool(pool), dialectPostgres)
+}
+
+func NewSQLiteMigrator(db *sql.DB) (*Migrator, error) {
+    return newMigrator(db, dialectSQLite)
+}
+
+func newMigrator(db *sql.DB, dialect string) (*Migrator, error) {
+    migrations, err := loadMigrations(dialect)
+    if err != nil {
+        return nil, err
+    }
+
+    return &Migrator{db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+func loadMigrations(dialect string) ([]Migration, error) {
+    dir := path.Join("migrations", dialect)
+    entries, err := fs.ReadDir(migrationsFS, dir)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read migrations: %w", err)
+    }
+
+    byVersion := make(map[int]*Migration)
+    for _, entry := range entries {
+        m := migrationFileRe.FindStringSubmatch(entry.Name())
+        if m == nil {
+            return nil, fmt.Errorf("unexpected migration file name %q", entry.Name())
+        }
+
+        version, _ := strconv.Atoi(m[1])
+        body, err := fs.ReadFile(migrationsFS, path.Join(dir, entry.Name()))
+        if err != nil {
+            return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+        }
+
+        migration, ok := byVersion[version]
+        if !ok {
+            migration = &Migration{Version: version, Name: m[2]}
+            byVersion[version] = migration
+        } else if migration.Name != m[2] {
+            return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, migration.Name, m[2])
+        }
+
+        if m[3] == "up" {
+            migration.Up = string(body)
+        } else {
+            migration.Down = string(body)
+        }
+    }
+
+    var migrations []Migration
+    for _, migration := range byVersion {
+        if migration.Up == "" || migration.Down == "" {
+            return nil, fmt.Errorf("migration %04d_%s must have both up and down files", migration.Version, migration.Name)
+        }
+        migrations = append(migrations, *migration)
+    }
+
+    sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+    return migrations, nil
+}
+
+// Up applies every pending migration in version order.
+func (m *Migrator) Up(ctx context.Context) error {
+    return m.withLock(ctx, func(conn *sql.Conn) error {
+        applied, err := m.appliedVersions(ctx, conn)
+        if err != nil {
+            return err
+        }
+
+        for _, migration := range m.migrations {
+            if _, ok := applied[migration.Version]; ok {
+                continue
+            }
+
+            err := m.inTx(ctx, conn, migration.Up,
+                `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+                migration.Version, migration.Name, time.Now().UTC())
+            if err != nil {
+                return fmt.Errorf("failed to apply migration %04d_%s: %w", migration.Version, migration.Name, err)
+            }
+
+            log.Printf("Applied migration %04d_%s", migration.Version, migration.Name)
+        }
+
+        return nil
+    })
+}
+
+// Down rolls back the last steps applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+    return m.withLock(ctx, func(conn *sql.Conn) error {
+        applied, err := m.appliedVersions(ctx, conn)
+        if err != nil {
+            return err
+        }
+
+        for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+            migration := m.migrations[i]
+            if _, ok := applied[migration.Version]; !ok {
+                continue
+            }
+
+            err := m.inTx(ctx, conn, migration.Down,
+                `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+            if err != nil {
+                return fmt.Errorf("failed to roll back migration %04d_%s: %w", migration.Version, migration.Name, err)
+            }
+
+            log.Printf("Rolled back migration %04d_%s", migration.Version, migration.Name)
+            steps--
+        }
+
+        return nil
+    })
+}
+
+// Status lists every known migration and whether it has been applied.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+    conn, err := m.db.Conn(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to acquire connection: %w", err)
+    }
+    defer conn.Close()
+
+    applied, err := m.appliedVersions(ctx, conn)
+    if err != nil {
+        return nil, err
+    }
+
+    statuses := make([]MigrationStatus, 0, len(m.migrations))
+    for _, migration := range m.migrations {
+        appliedAt, ok := applied[migration.Version]
+        statuses = append(statuses, MigrationStatus{Migration: migration, Applied: ok, AppliedAt: appliedAt})
+    }
+
+    return statuses, nil
+}
+
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+    conn, err := m.db.Conn(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to acquire connection: %w", err)
+    }
+    defer conn.Close()
+
+    // SQLite serialises writers on the database file, so only PostgreSQL needs
+    // an explicit lock across replicas.
+    if m.dialect == dialectPostgres {
+        if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+            return fmt.Errorf("failed to acquire migration lock: %w", err)
+        }
+        defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+    }
+
+    return fn(conn)
+}
+
+func (m *Migrator) appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+    query := `
+    CREATE TABLE IF NOT EXISTS schema_migrations (
+        version BIGINT PRIMARY KEY,
+        name VARCHAR(255) NOT NULL,
+        applied_at TIMESTAMP NOT NULL
+    );`
+
+    if _, err := conn.ExecContext(ctx, query); err != nil {
+        return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+    }
+
+    rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+    if err != nil {
+        return nil, fmt.Errorf("failed to query schema_migrations: %w", err)
+    }
+    defer rows.Close()
+
+    applied := make(map[int]time.Time)
+    for rows.Next() {
+        var version int
+        var appliedAt time.Time
+        if err := rows.Scan(&version, &appliedAt); err != nil {
+            return nil, fmt.Errorf("failed to scan migration: %w", err)
+        }
+        applied[version] = appliedAt
+    }
+
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("rows iteration error: %w", err)
+    }
+
+    return applied, nil
+}
+
+// inTx runs a migration script and its schema_migrations bookkeeping statement atomically.
+func (m *Migrator) inTx(ctx context.Context, conn *sql.Conn, script, bookkeeping string, args ...interface{}) error {
+    tx, err := conn.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if _, err := tx.ExecContext(ctx, script); err != nil {
+        return err
+    }
+
+    if _, err := tx.ExecContext(ctx, bookkeeping, args...); err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
@@ -0,0 +1,11 @@
+CREATE TABLE projects (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    delete_policy VARCHAR(16) NOT NULL DEFAULT 'block' CHECK (delete_policy IN ('block', 'cascade')),
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+ALTER TABLE tasks ADD COLUMN project_id INTEGER REFERENCES projects (id);
+
+CREATE INDEX tasks_project_id_idx ON tasks (project_id);
@@ -0,0 +1,11 @@
+-- SQLite cannot add a column with a non-constant default, so existing rows
+-- are backfilled with the migration time.
+ALTER TABLE tasks ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+ALTER TABLE tasks ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+ALTER TABLE tasks ADD COLUMN completed_at TIMESTAMP;
+
+UPDATE tasks SET
+    created_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'),
+    updated_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now');
+
+UPDATE tasks SET completed_at = updated_at WHERE done;
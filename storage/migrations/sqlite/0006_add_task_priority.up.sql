@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN priority SMALLINT NOT NULL DEFAULT 2 CHECK (priority BETWEEN 1 AND 4);
+
+CREATE INDEX tasks_done_priority_idx ON tasks (done, priority);
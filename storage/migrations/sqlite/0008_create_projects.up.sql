@@ -0,0 +1,11 @@
+CREATE TABLE projects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    delete_policy VARCHAR(16) NOT NULL DEFAULT 'block' CHECK (delete_policy IN ('block', 'cascade')),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+ALTER TABLE tasks ADD COLUMN project_id INTEGER REFERENCES projects (id);
+
+CREATE INDEX tasks_project_id_idx ON tasks (project_id);
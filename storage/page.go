@@ -0,0 +1,114 @@
+package storage
+
+import (
+    "fmt"
+    "strings"
+
+    "todo-golang/internal/filter"
+)
+
+// Page selects one keyset page of a task listing: up to Limit tasks, in the
+// order given by Sort, that come strictly after the position After. After
+// holds one value per key of Order() and is nil for the first page. A zero
+// Limit means no limit.
+type Page struct {
+    Limit int
+    Sort  []filter.SortKey
+    After []interface{}
+}
+
+// Order returns the effective sort keys of the page: Sort followed by the task
+// ID as a tie-breaker, so that the order is total and stable between requests.
+func (p Page) Order() []filter.SortKey {
+    keys := append([]filter.SortKey(nil), p.Sort...)
+    for _, key := range keys {
+        if key.Field.Name == "id" {
+            return keys
+        }
+    }
+
+    id, _ := filter.LookupField("id")
+    return append(keys, filter.SortKey{Field: id})
+}
+
+// buildListQuery returns the task listing query with the filter expression
+// and the keyset page applied. It lists the tasks in the trash if trashed is
+// set and the other tasks otherwise. PostgreSQL and SQLite share the same
+// syntax apart from the details handled by compileFilter.
+func buildListQuery(dialect string, expr filter.Expr, page Page, trashed bool) (string, []interface{}) {
+    var where []string
+    var args []interface{}
+
+    if trashed {
+        where = append(where, "deleted_at IS NOT NULL")
+    } else {
+        where = append(where, "deleted_at IS NULL")
+    }
+
+    if expr != nil {
+        where = append(where, compileFilter(expr, dialect, &args))
+    }
+
+    order := page.Order()
+    if page.After != nil {
+        where = append(where, keysetCondition(order, page.After, &args))
+    }
+
+    query := "SELECT " + taskColumns + " FROM tasks WHERE " + strings.Join(where, " AND ")
+
+    var orderBy []string
+    for _, key := range order {
+        dir := "ASC"
+        if key.Desc {
+            dir = "DESC"
+        }
+        orderBy = append(orderBy, sortColumn(key.Field, &args)+" "+dir)
+    }
+    query += " ORDER BY " + strings.Join(orderBy, ", ")
+
+    if page.Limit > 0 {
+        args = append(args, page.Limit)
+        query += fmt.Sprintf(" LIMIT $%d", len(args))
+    }
+
+    return query, args
+}
+
+// keysetCondition matches the rows that come after the position values in the
+// order given by keys: (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ..., with the
+// comparison flipped for descending keys.
+func keysetCondition(keys []filter.SortKey, values []interface{}, args *[]interface{}) string {
+    var alternatives []string
+    for i, key := range keys {
+        var terms []string
+        for j := 0; j < i; j++ {
+            column := sortColumn(keys[j].Field, args)
+            *args = append(*args, values[j])
+            terms = append(terms, fmt.Sprintf("%s = $%d", column, len(*args)))
+        }
+
+        op := ">"
+        if key.Desc {
+            op = "<"
+        }
+        column := sortColumn(key.Field, args)
+        *args = append(*args, values[i])
+        terms = append(terms, fmt.Sprintf("%s %s $%d", column, op, len(*args)))
+
+        alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+    }
+
+    return "(" + strings.Join(alternatives, " OR ") + ")"
+}
+
+// sortColumn returns the SQL expression a field is ordered by. NULLs of
+// nullable fields are replaced with filter.NullSortValue, matching the values
+// stored in pagination cursors and the in-memory ordering.
+func sortColumn(field *filter.Field, args *[]interface{}) string {
+    if !field.Nullable {
+        return field.Column
+    }
+
+    *args = append(*args, filter.NullSortValue)
+    return fmt.Sprintf("COALESCE(%s, $%d)", field.Column, len(*args))
+}
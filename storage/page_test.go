@@ -0,0 +1,172 @@
+package storage
+
+import (
+    "context"
+    "path/filepath"
+    "reflect"
+    "slices"
+    "sort"
+    "testing"
+    "time"
+
+    "todo-golang/internal/filter"
+    "todo-golang/internal/model"
+)
+
+func TestKeysetCondition(t *testing.T) {
+    due := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+    null := filter.NullSortValue
+
+    tests := []struct {
+        sort     string
+        after    []interface{}
+        prior    []interface{}
+        want     string
+        wantArgs []interface{}
+    }{
+        {
+            sort:     "id",
+            after:    []interface{}{5},
+            want:     "((id > $1))",
+            wantArgs: []interface{}{5},
+        },
+        {
+            sort:     "-priority",
+            after:    []interface{}{3, 7},
+            want:     "((priority < $1) OR (priority = $2 AND id > $3))",
+            wantArgs: []interface{}{3, 3, 7},
+        },
+        {
+            sort:     "due_at",
+            after:    []interface{}{null, 4},
+            want:     "((COALESCE(due_at, $1) > $2) OR (COALESCE(due_at, $3) = $4 AND id > $5))",
+            wantArgs: []interface{}{null, null, null, null, 4},
+        },
+        {
+            // An explicit id key is the tie-breaker and is not repeated; the
+            // placeholders continue after the arguments already bound.
+            sort:     "-completed_at,-id",
+            after:    []interface{}{due, 9},
+            prior:    []interface{}{false},
+            want:     "((COALESCE(completed_at, $2) < $3) OR (COALESCE(completed_at, $4) = $5 AND id < $6))",
+            wantArgs: []interface{}{false, null, due, null, due, 9},
+        },
+        {
+            sort:     "done,-due_at",
+            after:    []interface{}{true, due, 2},
+            want:     "((done > $1) OR (done = $2 AND COALESCE(due_at, $3) < $4) OR (done = $5 AND COALESCE(due_at, $6) = $7 AND id > $8))",
+            wantArgs: []interface{}{true, true, null, due, true, null, due, 2},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.sort, func(t *testing.T) {
+            order := Page{Sort: filter.MustParseSort(tt.sort)}.Order()
+            args := append([]interface{}(nil), tt.prior...)
+
+            got := keysetCondition(order, tt.after, &args)
+            if got != tt.want {
+                t.Errorf("keysetCondition = %s, want %s", got, tt.want)
+            }
+            if !reflect.DeepEqual(args, tt.wantArgs) {
+                t.Errorf("args = %v, want %v", args, tt.wantArgs)
+            }
+        })
+    }
+}
+
+// TestKeysetBoundaries checks that a page starting after any task of a listing
+// holds exactly the tasks that follow it, for orders with ties and NULLs, and
+// that the SQL order agrees with filter.CompareSortValues used by the memory
+// repository.
+func TestKeysetBoundaries(t *testing.T) {
+    ctx := context.Background()
+    r := newTestSQLiteRepository(t)
+
+    base := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+    later := base.Add(time.Hour)
+    rows := []struct {
+        title     string
+        priority  model.Priority
+        due       *time.Time
+        created   time.Time
+        completed *time.Time
+    }{
+        {"A", model.PriorityNormal, &base, base, nil},
+        {"B", model.PriorityHigh, nil, base, nil},
+        {"C", model.PriorityNormal, nil, later, &base},
+        {"D", model.PriorityHigh, &base, base, nil},
+        {"E", model.PriorityLow, &later, later, &base},
+        {"F", model.PriorityNormal, &base, base, nil},
+        {"G", model.PriorityHigh, nil, later, &later},
+    }
+    for _, row := range rows {
+        _, err := r.db.exec(ctx, `
+        INSERT INTO tasks (title, description, done, due_at, created_at, updated_at, completed_at, priority, recurrence)
+        VALUES ($1, '', $2, $3, $4, $4, $5, $6, '')`,
+            row.title, row.completed != nil, row.due, row.created, row.completed, row.priority)
+        if err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    for _, spec := range []string{"due_at", "-due_at", "-priority,due_at", "done,-completed_at", "-created_at", "due_at,-id", "-completed_at,title"} {
+        t.Run(spec, func(t *testing.T) {
+            page := Page{Sort: filter.MustParseSort(spec)}
+            order := page.Order()
+
+            all := listPage(t, r, page)
+            want := slices.Clone(all)
+            sort.SliceStable(want, func(i, j int) bool {
+                return filter.CompareSortValues(want[i], order, filter.SortValues(want[j], order)) < 0
+            })
+            if !slices.Equal(taskIDs(all), taskIDs(want)) {
+                t.Fatalf("SQL order = %v, want %v", taskIDs(all), taskIDs(want))
+            }
+
+            for i, task := range all {
+                page.After = filter.SortValues(task, order)
+                got := listPage(t, r, page)
+                if !slices.Equal(taskIDs(got), taskIDs(all[i+1:])) {
+                    t.Errorf("after task %d: got %v, want %v", task.ID, taskIDs(got), taskIDs(all[i+1:]))
+                }
+            }
+        })
+    }
+}
+
+func newTestSQLiteRepository(t *testing.T) *SQLiteTaskRepository {
+    t.Helper()
+    db, err := NewSQLiteDB("sqlite://" + filepath.Join(t.TempDir(), "todo.db"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { db.Close() })
+
+    migrator, err := NewSQLiteMigrator(db)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := migrator.Up(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    return NewSQLiteTaskRepository(db, time.Minute)
+}
+
+func listPage(t *testing.T, r *SQLiteTaskRepository, page Page) []model.Task {
+    t.Helper()
+    query, args := buildListQuery(r.dialect.name, nil, page, false)
+    tasks, err := r.listTasks(context.Background(), query, args)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return tasks
+}
+
+func taskIDs(tasks []model.Task) []int {
+    ids := make([]int, len(tasks))
+    for i, task := range tasks {
+        ids[i] = task.ID
+    }
+    return ids
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+var postgresDialect = sqlDialect{
+    name:     dialectPostgres,
+    lockRow:  " FOR UPDATE",
+    shareRow: " FOR SHARE",
+    // The lock mode conflicts with itself but not with readers.
+    lockDependencies: `LOCK TABLE task_dependencies IN SHARE ROW EXCLUSIVE MODE`,
+}
+
+type PostgresTaskRepository struct {
+    sqlRepository
+}
+
+// NewPostgresTaskRepository returns a repository whose methods each give up
+// after queryTimeout; zero means no timeout.
+func NewPostgresTaskRepository(db *pgxpool.Pool, queryTimeout time.Duration) *PostgresTaskRepository {
+    return &PostgresTaskRepository{sqlRepository{db: newPgxDB(db), dialect: postgresDialect, queryTimeout: queryTimeout}}
+}
+
+// PoolConfig sizes a PostgreSQL connection pool. Zero fields keep the pgx
+// defaults.
+type PoolConfig struct {
+    MaxConns        int32
+    MinConns        int32
+    MaxConnLifetime time.Duration
+    MaxConnIdleTime time.Duration
+}
+
+func NewPostgresDB(dsn string, pool PoolConfig) (*pgxpool.Pool, error) {
+    cfg, err := pgxpool.ParseConfig(dsn)
+    if err != nil {
+        return nil, fmt.Errorf("failed to parse database URL: %w", err)
+    }
+
+    if pool.MaxConns > 0 {
+        cfg.MaxConns = pool.MaxConns
+    }
+    if pool.MinConns > 0 {
+        cfg.MinConns = pool.MinConns
+    }
+    if pool.MaxConnLifetime > 0 {
+        cfg.MaxConnLifetime = pool.MaxConnLifetime
+    }
+    if pool.MaxConnIdleTime > 0 {
+        cfg.MaxConnIdleTime = pool.MaxConnIdleTime
+    }
+
+    dbpool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create connection pool: %w", err)
+    }
+
+    if err := dbpool.Ping(context.Background()); err != nil {
+        return nil, fmt.Errorf("failed to ping database: %w", err)
+    }
+
+    log.Println("Connected to PostgreSQL")
+
+    return dbpool, nil
+}
+
@@ -0,0 +1,179 @@
+package storage
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "log"
+
+    "todo-golang/internal/model"
+)
+
+// ProjectRepository stores projects. Methods addressing a project by ID fail
+// with ErrProjectNotFound if it does not exist.
+type ProjectRepository interface {
+    GetProjects(ctx context.Context) ([]model.Project, error)
+    GetProject(ctx context.Context, id int) (model.Project, error)
+    AddProject(ctx context.Context, project model.Project) (model.Project, error)
+    UpdateProject(ctx context.Context, project model.Project) (model.Project, error)
+    // DeleteProject removes a project according to its delete policy: it fails
+    // with ErrProjectHasTasks under the block policy and moves the project's
+    // tasks to the trash under the cascade policy. The project's tasks in the
+    // trash, including those just moved there, are left without a project and
+    // can still be restored.
+    DeleteProject(ctx context.Context, id int) error
+}
+
+const (
+    projectColumns     = "id, name, delete_policy, created_at, updated_at"
+    projectExistsQuery = `SELECT id FROM projects WHERE id = $1`
+    projectTasksQuery  = `SELECT EXISTS (SELECT 1 FROM tasks WHERE project_id = $1 AND deleted_at IS NULL)`
+)
+
+// scanProject reads a row selected with projectColumns.
+func scanProject(row rowScanner, project *model.Project) error {
+    if err := row.Scan(&project.ID, &project.Name, &project.DeletePolicy, &project.CreatedAt, &project.UpdatedAt); err != nil {
+        return err
+    }
+
+    project.CreatedAt = project.CreatedAt.UTC()
+    project.UpdatedAt = project.UpdatedAt.UTC()
+    return nil
+}
+
+func (r *sqlRepository) GetProjects(ctx context.Context) ([]model.Project, error) {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    projects := []model.Project{}
+    query := `SELECT ` + projectColumns + ` FROM projects ORDER BY id`
+
+    rows, err := r.db.query(ctx, query)
+    if err != nil {
+        return nil, fmt.Errorf("failed to query projects: %w", err)
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var project model.Project
+        if err := scanProject(rows, &project); err != nil {
+            return nil, fmt.Errorf("failed to scan project: %w", err)
+        }
+        projects = append(projects, project)
+    }
+
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("rows iteration error: %w", err)
+    }
+
+    return projects, nil
+}
+
+func (r *sqlRepository) GetProject(ctx context.Context, id int) (model.Project, error) {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    var project model.Project
+    query := `SELECT ` + projectColumns + ` FROM projects WHERE id = $1`
+
+    err := scanProject(r.db.queryRow(ctx, query, id), &project)
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return project, ErrProjectNotFound
+        }
+        return project, fmt.Errorf("failed to get project: %w", err)
+    }
+
+    return project, nil
+}
+
+func (r *sqlRepository) AddProject(ctx context.Context, project model.Project) (model.Project, error) {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    var created model.Project
+    query := `
+    INSERT INTO projects (name, delete_policy, created_at, updated_at)
+    VALUES ($1, $2, $3, $3)
+    RETURNING ` + projectColumns
+
+    row := r.db.queryRow(ctx, query, project.Name, project.DeletePolicy, now())
+    if err := scanProject(row, &created); err != nil {
+        return created, fmt.Errorf("failed to add project: %w", err)
+    }
+
+    log.Println("Project added successfully")
+    return created, nil
+}
+
+func (r *sqlRepository) UpdateProject(ctx context.Context, project model.Project) (model.Project, error) {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    var updated model.Project
+    query := `
+    UPDATE projects SET name = $2, delete_policy = $3, updated_at = $4
+    WHERE id = $1
+    RETURNING ` + projectColumns
+
+    row := r.db.queryRow(ctx, query, project.ID, project.Name, project.DeletePolicy, now())
+    if err := scanProject(row, &updated); err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return updated, ErrProjectNotFound
+        }
+        return updated, fmt.Errorf("failed to update project: %w", err)
+    }
+
+    log.Println("Project updated successfully")
+    return updated, nil
+}
+
+func (r *sqlRepository) DeleteProject(ctx context.Context, id int) error {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    tx, err := r.db.begin(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to delete project: %w", err)
+    }
+    defer tx.rollback(ctx)
+
+    // Lock the project so that no task is added to it while it is being deleted.
+    var policy string
+    err = tx.queryRow(ctx, `SELECT delete_policy FROM projects WHERE id = $1`+r.dialect.lockRow, id).Scan(&policy)
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return ErrProjectNotFound
+        }
+        return fmt.Errorf("failed to delete project: %w", err)
+    }
+
+    if policy != model.ProjectDeleteCascade {
+        var hasTasks bool
+        if err := tx.queryRow(ctx, projectTasksQuery, id).Scan(&hasTasks); err != nil {
+            return fmt.Errorf("failed to delete project: %w", err)
+        }
+        if hasTasks {
+            return ErrProjectHasTasks
+        }
+    }
+
+    // Under the block policy only tasks in the trash are left to detach.
+    if _, err := tx.exec(ctx, trashProjectQuery, id, now()); err != nil {
+        return fmt.Errorf("failed to delete project tasks: %w", err)
+    }
+    if _, err := tx.exec(ctx, detachProjectQuery, id); err != nil {
+        return fmt.Errorf("failed to delete project tasks: %w", err)
+    }
+
+    if _, err := tx.exec(ctx, `DELETE FROM projects WHERE id = $1`, id); err != nil {
+        return fmt.Errorf("failed to delete project: %w", err)
+    }
+    if err := tx.commit(ctx); err != nil {
+        return fmt.Errorf("failed to delete project: %w", err)
+    }
+
+    log.Println("Project deleted successfully")
+    return nil
+}
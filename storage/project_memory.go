@@ -0,0 +1,104 @@
+package storage
+
+import (
+    "context"
+    "log"
+    "sort"
+
+    "todo-golang/internal/model"
+)
+
+func (r *MemoryTaskRepository) GetProjects(ctx context.Context) ([]model.Project, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    projects := make([]model.Project, 0, len(r.projects))
+    for _, project := range r.projects {
+        projects = append(projects, project)
+    }
+    sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+
+    return projects, nil
+}
+
+func (r *MemoryTaskRepository) GetProject(ctx context.Context, id int) (model.Project, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    project, ok := r.projects[id]
+    if !ok {
+        return model.Project{}, ErrProjectNotFound
+    }
+
+    return project, nil
+}
+
+func (r *MemoryTaskRepository) AddProject(ctx context.Context, project model.Project) (model.Project, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    at := now()
+    project.ID = r.nextProjectID
+    project.CreatedAt = at
+    project.UpdatedAt = at
+    r.nextProjectID++
+    r.projects[project.ID] = project
+
+    log.Println("Project added successfully")
+    return project, nil
+}
+
+func (r *MemoryTaskRepository) UpdateProject(ctx context.Context, project model.Project) (model.Project, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    current, ok := r.projects[project.ID]
+    if !ok {
+        return model.Project{}, ErrProjectNotFound
+    }
+
+    project.CreatedAt = current.CreatedAt
+    project.UpdatedAt = now()
+    r.projects[project.ID] = project
+
+    log.Println("Project updated successfully")
+    return project, nil
+}
+
+func (r *MemoryTaskRepository) DeleteProject(ctx context.Context, id int) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    project, ok := r.projects[id]
+    if !ok {
+        return ErrProjectNotFound
+    }
+
+    var taskIDs, liveIDs []int
+    for _, task := range r.tasks {
+        if task.ProjectID != nil && *task.ProjectID == id {
+            taskIDs = append(taskIDs, task.ID)
+            if task.DeletedAt == nil {
+                liveIDs = append(liveIDs, task.ID)
+            }
+        }
+    }
+
+    if len(liveIDs) > 0 && project.DeletePolicy != model.ProjectDeleteCascade {
+        return ErrProjectHasTasks
+    }
+
+    at := now()
+    for _, taskID := range liveIDs {
+        r.trash(taskID, at)
+    }
+    for _, taskID := range taskIDs {
+        task := r.tasks[taskID]
+        task.ProjectID = nil
+        r.tasks[taskID] = task
+    }
+    delete(r.projects, id)
+
+    log.Println("Project deleted successfully")
+    return nil
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+    "time"
+
+    "todo-golang/internal/model"
+    "todo-golang/internal/recurrence"
+)
+
+// nextOccurrence returns the task to create when a recurring task is completed
+// at the given time: a copy due at the first occurrence after both its due
+// time and the completion, carrying the rest of the series. Occurrences missed
+// while the task was overdue are skipped. ok is false for one-off tasks and at
+// the end of a series.
+func nextOccurrence(task model.Task, at time.Time) (model.Task, bool) {
+    if task.Recurrence == "" || task.DueAt == nil {
+        return model.Task{}, false
+    }
+
+    // Rules are validated when tasks are saved.
+    rule, err := recurrence.Parse(task.Recurrence)
+    if err != nil {
+        return model.Task{}, false
+    }
+
+    due, rest, ok := rule.Next(*task.DueAt, at)
+    if !ok {
+        return model.Task{}, false
+    }
+
+    return model.Task{
+        Title:       task.Title,
+        Description: task.Description,
+        ProjectID:   task.ProjectID,
+        ParentID:    task.ParentID,
+        Priority:    task.Priority,
+        Tags:        task.Tags,
+        DueAt:       &due,
+        Recurrence:  rest.String(),
+    }, true
+}
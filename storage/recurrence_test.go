@@ -0,0 +1,102 @@
+package storage
+
+import (
+    "reflect"
+    "testing"
+    "time"
+    _ "time/tzdata"
+
+    "todo-golang/internal/model"
+)
+
+func TestNextOccurrence(t *testing.T) {
+    due := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+    beforeDST := time.Date(2026, 3, 28, 8, 0, 0, 0, time.UTC)
+    project, parent := 3, 7
+    base := model.Task{
+        ID:          1,
+        Title:       "Standup",
+        Description: "Daily sync",
+        Done:        true,
+        ProjectID:   &project,
+        ParentID:    &parent,
+        Priority:    model.PriorityHigh,
+        Tags:        []string{"team"},
+        DueAt:       &due,
+        CreatedAt:   due.AddDate(0, 0, -7),
+    }
+    with := func(recurrence string, dueAt *time.Time) model.Task {
+        task := base
+        task.Recurrence = recurrence
+        task.DueAt = dueAt
+        return task
+    }
+    at := func(s string) time.Time {
+        tm, err := time.Parse(time.RFC3339, s)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return tm
+    }
+
+    tests := []struct {
+        name       string
+        task       model.Task
+        at         time.Time
+        due        string
+        recurrence string
+    }{
+        {name: "one-off", task: with("", &due), at: at("2026-01-05T10:00:00Z")},
+        {name: "without due", task: with("FREQ=DAILY", nil), at: at("2026-01-05T10:00:00Z")},
+        {name: "last of count", task: with("FREQ=DAILY;COUNT=1", &due), at: at("2026-01-05T10:00:00Z")},
+        {name: "past until", task: with("FREQ=WEEKLY;UNTIL=20260110", &due), at: at("2026-01-05T10:00:00Z")},
+        {
+            name: "on time", task: with("FREQ=WEEKLY;BYDAY=MO,TH", &due), at: at("2026-01-05T10:00:00Z"),
+            due: "2026-01-08T09:00:00Z", recurrence: "FREQ=WEEKLY;BYDAY=MO,TH",
+        },
+        {
+            name: "completed early", task: with("FREQ=WEEKLY", &due), at: at("2026-01-01T10:00:00Z"),
+            due: "2026-01-12T09:00:00Z", recurrence: "FREQ=WEEKLY",
+        },
+        {
+            // Occurrences missed while overdue are skipped and use up COUNT.
+            name: "overdue", task: with("FREQ=WEEKLY;COUNT=5", &due), at: at("2026-01-20T10:00:00Z"),
+            due: "2026-01-26T09:00:00Z", recurrence: "FREQ=WEEKLY;COUNT=2",
+        },
+        {
+            // 09:00 in Berlin before and after the switch to summer time.
+            name: "time zone", task: with("FREQ=DAILY;TZID=Europe/Berlin", &beforeDST), at: at("2026-03-28T10:00:00Z"),
+            due: "2026-03-29T07:00:00Z", recurrence: "FREQ=DAILY;TZID=Europe/Berlin",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            next, ok := nextOccurrence(tt.task, tt.at)
+            if tt.due == "" {
+                if ok {
+                    t.Errorf("nextOccurrence = %+v, want none", next)
+                }
+                return
+            }
+            if !ok {
+                t.Fatalf("nextOccurrence: none, want one due %s", tt.due)
+            }
+
+            nextDue := at(tt.due)
+            want := model.Task{
+                Title:       base.Title,
+                Description: base.Description,
+                ProjectID:   base.ProjectID,
+                ParentID:    base.ParentID,
+                Priority:    base.Priority,
+                Tags:        base.Tags,
+                DueAt:       &nextDue,
+                Recurrence:  tt.recurrence,
+            }
+            if !reflect.DeepEqual(next, want) {
+                t.Errorf("nextOccurrence = %+v\nwant %+v", next, want)
+            }
+        })
+    }
+}
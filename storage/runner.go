@@ -0,0 +1,161 @@
+package storage
+
+import (
+    "context"
+    "database/sql"
+
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgconn"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+// queryRunner runs statements on a database or inside a transaction. It hides
+// the difference between pgx and database/sql from sqlRepository. Rows that
+// are not found are reported with an error matching sql.ErrNoRows.
+type queryRunner interface {
+    // exec runs a statement and returns the number of rows it affected.
+    exec(ctx context.Context, query string, args ...interface{}) (int64, error)
+    query(ctx context.Context, query string, args ...interface{}) (sqlRows, error)
+    queryRow(ctx context.Context, query string, args ...interface{}) rowScanner
+}
+
+type dbRunner interface {
+    queryRunner
+    begin(ctx context.Context) (txRunner, error)
+}
+
+type txRunner interface {
+    queryRunner
+    commit(ctx context.Context) error
+    // rollback is deferred after begin; it does nothing once committed.
+    rollback(ctx context.Context) error
+}
+
+type rowScanner interface {
+    Scan(dest ...interface{}) error
+}
+
+type sqlRows interface {
+    rowScanner
+    Next() bool
+    Err() error
+    Close()
+}
+
+// pgxRunner runs statements on a pgx pool or transaction. pgx.ErrNoRows
+// already matches sql.ErrNoRows.
+type pgxRunner struct {
+    q interface {
+        Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+        Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+        QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+    }
+}
+
+func (p pgxRunner) exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+    tag, err := p.q.Exec(ctx, query, args...)
+    if err != nil {
+        return 0, err
+    }
+    return tag.RowsAffected(), nil
+}
+
+func (p pgxRunner) query(ctx context.Context, query string, args ...interface{}) (sqlRows, error) {
+    rows, err := p.q.Query(ctx, query, args...)
+    if err != nil {
+        return nil, err
+    }
+    return rows, nil
+}
+
+func (p pgxRunner) queryRow(ctx context.Context, query string, args ...interface{}) rowScanner {
+    return p.q.QueryRow(ctx, query, args...)
+}
+
+type pgxDB struct {
+    pgxRunner
+    pool *pgxpool.Pool
+}
+
+func newPgxDB(pool *pgxpool.Pool) pgxDB {
+    return pgxDB{pgxRunner: pgxRunner{q: pool}, pool: pool}
+}
+
+func (d pgxDB) begin(ctx context.Context) (txRunner, error) {
+    tx, err := d.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    return pgxTx{pgxRunner: pgxRunner{q: tx}, tx: tx}, nil
+}
+
+type pgxTx struct {
+    pgxRunner
+    tx pgx.Tx
+}
+
+func (t pgxTx) commit(ctx context.Context) error { return t.tx.Commit(ctx) }
+
+func (t pgxTx) rollback(ctx context.Context) error { return t.tx.Rollback(ctx) }
+
+// stdRunner runs statements on a database/sql database or transaction.
+type stdRunner struct {
+    q interface {
+        ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+        QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+        QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+    }
+}
+
+func (s stdRunner) exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+    res, err := s.q.ExecContext(ctx, query, args...)
+    if err != nil {
+        return 0, err
+    }
+    return res.RowsAffected()
+}
+
+func (s stdRunner) query(ctx context.Context, query string, args ...interface{}) (sqlRows, error) {
+    rows, err := s.q.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, err
+    }
+    return stdRows{rows}, nil
+}
+
+func (s stdRunner) queryRow(ctx context.Context, query string, args ...interface{}) rowScanner {
+    return s.q.QueryRowContext(ctx, query, args...)
+}
+
+// stdRows drops the error of Close, which database/sql also reports from Err.
+type stdRows struct {
+    *sql.Rows
+}
+
+func (r stdRows) Close() { r.Rows.Close() }
+
+type stdDB struct {
+    stdRunner
+    db *sql.DB
+}
+
+func newStdDB(db *sql.DB) stdDB {
+    return stdDB{stdRunner: stdRunner{q: db}, db: db}
+}
+
+func (d stdDB) begin(ctx context.Context) (txRunner, error) {
+    tx, err := d.db.BeginTx(ctx, nil)
+    if err != nil {
+        return nil, err
+    }
+    return stdTx{stdRunner: stdRunner{q: tx}, tx: tx}, nil
+}
+
+type stdTx struct {
+    stdRunner
+    tx *sql.Tx
+}
+
+func (t stdTx) commit(ctx context.Context) error { return t.tx.Commit() }
+
+func (t stdTx) rollback(ctx context.Context) error { return t.tx.Rollback() }
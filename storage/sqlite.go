@@ -0,0 +1,63 @@
+package storage
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "log"
+    "strings"
+    "time"
+
+    _ "modernc.org/sqlite"
+)
+
+const sqliteScheme = "sqlite://"
+
+// SQLite needs no locks: the database has a single connection, which a
+// transaction holds until it ends.
+var sqliteDialect = sqlDialect{name: dialectSQLite}
+
+type SQLiteTaskRepository struct {
+    sqlRepository
+}
+
+// NewSQLiteTaskRepository returns a repository whose methods each give up
+// after queryTimeout; zero means no timeout.
+func NewSQLiteTaskRepository(db *sql.DB, queryTimeout time.Duration) *SQLiteTaskRepository {
+    return &SQLiteTaskRepository{sqlRepository{db: newStdDB(db), dialect: sqliteDialect, queryTimeout: queryTimeout}}
+}
+
+// IsSQLiteDSN reports whether dsn uses the sqlite:// scheme, e.g. sqlite:///var/lib/todo/todo.db.
+func IsSQLiteDSN(dsn string) bool {
+    return strings.HasPrefix(dsn, sqliteScheme)
+}
+
+func NewSQLiteDB(dsn string) (*sql.DB, error) {
+    path := strings.TrimPrefix(dsn, sqliteScheme)
+    if path == "" {
+        return nil, fmt.Errorf("sqlite DSN %q has no database path", dsn)
+    }
+
+    sep := "?"
+    if strings.Contains(path, "?") {
+        sep = "&"
+    }
+
+    db, err := sql.Open("sqlite", "file:"+path+sep+"_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite")
+    if err != nil {
+        return nil, fmt.Errorf("failed to open database: %w", err)
+    }
+
+    // SQLite allows a single writer at a time; serialising access through one
+    // connection avoids SQLITE_BUSY errors under concurrent requests.
+    db.SetMaxOpenConns(1)
+
+    if err := db.PingContext(context.Background()); err != nil {
+        db.Close()
+        return nil, fmt.Errorf("failed to ping database: %w", err)
+    }
+
+    log.Printf("Opened SQLite database %s", path)
+
+    return db, nil
+}
@@ -3,149 +3,666 @@ package storage
 import (
     "context"
     "database/sql"
+    "errors"
     "fmt"
     "log"
+    "time"
 
-    "todo-golang/internal/config"
-
-    "github.com/jackc/pgx/v5/pgxpool"
+    "todo-golang/internal/filter"
+    "todo-golang/internal/model"
 )
 
+// TaskRepository stores tasks. Methods addressing a task by ID fail with
+// ErrTaskNotFound if it does not exist or is in the trash. The SQL
+// repositories abort their queries when ctx is done.
 type TaskRepository interface {
-    GetAll() ([]model.Task, error)
-    GetByID(id int) (model.Task, error) 
-    Add(task model.Task) error
-    Delete(id int) error
-    MarkDone(id int) error
-    GetFiltered(done *bool) ([]model.Task, error)
+    GetAll(ctx context.Context, page Page) ([]model.Task, error)
+    GetByID(ctx context.Context, id int) (model.Task, error) 
+    Add(ctx context.Context, task model.Task) (model.Task, error)
+    // Update replaces the stored task. A change of Done is recorded in the
+    // history on behalf of actor, as by MarkDone and Reopen; completing the
+    // task fails like MarkDone without options.
+    Update(ctx context.Context, task model.Task, actor string) (model.Task, error)
+    // Delete moves the task and its subtasks to the trash. Tasks in the trash
+    // are left out of every other method until they are restored.
+    Delete(ctx context.Context, id int) error
+    // Trash lists the tasks in the trash.
+    Trash(ctx context.Context, expr filter.Expr, page Page) ([]model.Task, error)
+    // Restore takes the task out of the trash along with the subtasks deleted
+    // with it. It fails with ErrParentDeleted if the task's parent is
+    // still in the trash.
+    Restore(ctx context.Context, id int) error
+    // Purge permanently removes the tasks put in the trash before the given
+    // time and returns how many were removed.
+    Purge(ctx context.Context, before time.Time) (int, error)
+    // MarkDone fails with ErrOpenSubtasks if any task below id is not done,
+    // and with ErrBlocked if the task has open blockers; opts relaxes both
+    // checks.
+    MarkDone(ctx context.Context, id int, actor string, opts DoneOptions) error
+    Reopen(ctx context.Context, id int, actor string) error
+    History(ctx context.Context, id int) ([]model.TaskEvent, error)
+    GetFiltered(ctx context.Context, expr filter.Expr, page Page) ([]model.Task, error)
+    // Subtree returns the task with all its subtasks, to any depth.
+    Subtree(ctx context.Context, id int) (model.TaskTree, error)
+    AddTag(ctx context.Context, taskID int, tag string) error
+    RemoveTag(ctx context.Context, taskID int, tag string) error
+    Tags(ctx context.Context) ([]model.TagCount, error)
+    // AddBlocker records that taskID cannot be done before blockerID. It fails
+    // with ErrDependencyCycle if blockerID already depends on taskID.
+    AddBlocker(ctx context.Context, taskID, blockerID int) error
+    RemoveBlocker(ctx context.Context, taskID, blockerID int) error
+}
+
+// DoneOptions relaxes the checks made by MarkDone.
+type DoneOptions struct {
+    // Cascade marks the open subtasks done along with the task.
+    Cascade bool
+    // Force completes the task even if it is blocked by open tasks.
+    Force bool
 }
 
-type PostgresTaskRepository struct {
-    db *pgxpool.Pool
+// sqlDialect describes what differs between the databases sqlRepository runs on.
+type sqlDialect struct {
+    // name selects the migrations and the filter syntax.
+    name string
+    // lockRow is appended to a SELECT to lock the selected rows until the end
+    // of the transaction.
+    lockRow string
+    // shareRow is appended to a SELECT to keep the selected rows from being
+    // changed or deleted until the end of the transaction.
+    shareRow string
+    // lockDependencies serialises insertions into task_dependencies, which
+    // could otherwise each pass the cycle check and together close a cycle.
+    lockDependencies string
 }
 
-func NewPostgresTaskRepository(db *pgxpool.Pool) *PostgresTaskRepository {
-    return &PostgresTaskRepository{db: db}
+// sqlRepository is the TaskRepository and ProjectRepository shared by the SQL
+// backends. PostgreSQL and SQLite run the same statements; what differs is in
+// the runner and the dialect.
+type sqlRepository struct {
+    db           dbRunner
+    dialect      sqlDialect
+    queryTimeout time.Duration
 }
 
-func NewPostgresDB(dsn string) (*pgxpool.Pool, error) {
-    dbpool, err := pgxpool.New(context.Background(), dsn)
+// taskColumns lists the columns of tasks in the order expected by scanTask.
+const taskColumns = "id, title, description, done, project_id, parent_id, priority, due_at, recurrence, created_at, updated_at, completed_at, deleted_at"
+
+// scanTask reads a row selected with taskColumns. It is shared by the SQL repositories.
+func scanTask(row rowScanner, task *model.Task) error {
+    if err := row.Scan(&task.ID, &task.Title, &task.Description, &task.Done, &task.ProjectID, &task.ParentID, &task.Priority, &task.DueAt, &task.Recurrence, &task.CreatedAt, &task.UpdatedAt, &task.CompletedAt, &task.DeletedAt); err != nil {
+        return err
+    }
+
+    task.DueAt = utc(task.DueAt)
+    task.CreatedAt = task.CreatedAt.UTC()
+    task.UpdatedAt = task.UpdatedAt.UTC()
+    task.CompletedAt = utc(task.CompletedAt)
+    task.DeletedAt = utc(task.DeletedAt)
+    return nil
+}
+
+// utc converts an optional timestamp to UTC. Timestamps are always stored in
+// UTC because SQLite compares them as text.
+func utc(t *time.Time) *time.Time {
+    if t == nil {
+        return nil
+    }
+    u := t.UTC()
+    return &u
+}
+
+// withTimeout bounds a repository call by the repository's query timeout,
+// unless it is zero.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+    if timeout <= 0 {
+        return context.WithCancel(ctx)
+    }
+    return context.WithTimeout(ctx, timeout)
+}
+
+// now returns the current time in UTC, truncated to the microsecond precision
+// of PostgreSQL timestamps so that every repository reports the same values.
+func now() time.Time {
+    return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// completedAt returns the completion time for a task created in the given state.
+func completedAt(done bool, at time.Time) *time.Time {
+    if !done {
+        return nil
+    }
+    return &at
+}
+
+func (r *sqlRepository) GetAll(ctx context.Context, page Page) ([]model.Task, error) {
+    return r.GetFiltered(ctx, nil, page)
+}
+
+func (r *sqlRepository) GetByID(ctx context.Context, id int) (model.Task, error) {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    var task model.Task
+    query := `SELECT ` + taskColumns + ` FROM tasks WHERE id = $1 AND deleted_at IS NULL`
+
+    row := r.db.queryRow(ctx, query, id)
+    err := scanTask(row, &task)
+
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return task, ErrTaskNotFound
+        }
+        return task, fmt.Errorf("failed to get task: %w", err)
+    }
+
+    tasks := []model.Task{task}
+    if err := r.loadTags(ctx, tasks); err != nil {
+        return task, fmt.Errorf("failed to get task: %w", err)
+    }
+    if err := r.loadBlockers(ctx, tasks); err != nil {
+        return task, fmt.Errorf("failed to get task: %w", err)
+    }
+
+    return tasks[0], nil
+}
+
+func (r *sqlRepository) Add(ctx context.Context, task model.Task) (model.Task, error) {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    var created model.Task
+
+    tags, err := model.NormalizeTags(task.Tags)
+    if err != nil {
+        return created, invalid(err)
+    }
+
+    tx, err := r.db.begin(ctx)
     if err != nil {
-        return nil, fmt.Errorf("failed to create connection pool: %w", err)
+        return created, fmt.Errorf("failed to add task: %w", err)
     }
+    defer tx.rollback(ctx)
 
-    if err := dbpool.Ping(context.Background()); err != nil {
-        return nil, fmt.Errorf("failed to ping database: %w", err)
+    if err := r.checkProject(ctx, tx, task.ProjectID); err != nil {
+        return created, err
+    }
+    if err := r.checkParent(ctx, tx, 0, task.ParentID); err != nil {
+        return created, err
     }
 
-    log.Println("Connected to PostgreSQL")
+    created, err = r.insertTask(ctx, tx, task, now())
+    if err != nil {
+        return created, fmt.Errorf("failed to add task: %w", err)
+    }
 
-    if err := createTasksTable(context.Background(), dbpool); err != nil {
-        return nil, fmt.Errorf("failed to create tasks table: %w", err)
+    if err := r.replaceTags(ctx, tx, created.ID, tags); err != nil {
+        return created, fmt.Errorf("failed to add task: %w", err)
     }
+    if err := tx.commit(ctx); err != nil {
+        return created, fmt.Errorf("failed to add task: %w", err)
+    }
+    created.Tags = tags
+    created.BlockedBy = []int{}
 
-    return dbpool, nil
+    log.Println("Task added successfully")
+    return created, nil
 }
 
-func createTasksTable(ctx context.Context, db *pgxpool.Pool) error {
+func (r *sqlRepository) Update(ctx context.Context, task model.Task, actor string) (model.Task, error) {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    var updated model.Task
+
+    tags, err := model.NormalizeTags(task.Tags)
+    if err != nil {
+        return updated, invalid(err)
+    }
+
+    tx, err := r.db.begin(ctx)
+    if err != nil {
+        return updated, fmt.Errorf("failed to update task: %w", err)
+    }
+    defer tx.rollback(ctx)
+
+    var wasDone bool
+    err = tx.queryRow(ctx, `SELECT done FROM tasks WHERE id = $1 AND deleted_at IS NULL`+r.dialect.lockRow, task.ID).Scan(&wasDone)
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return updated, ErrTaskNotFound
+        }
+        return updated, fmt.Errorf("failed to update task: %w", err)
+    }
+
+    if err := r.checkProject(ctx, tx, task.ProjectID); err != nil {
+        return updated, err
+    }
+    if err := r.checkParent(ctx, tx, task.ID, task.ParentID); err != nil {
+        return updated, err
+    }
+    completing := task.Done && !wasDone
+    if completing {
+        if err := r.checkCompletable(ctx, tx, task.ID); err != nil {
+            return updated, err
+        }
+    }
+
     query := `
-    CREATE TABLE IF NOT EXISTS tasks (
-        id SERIAL PRIMARY KEY,
-        title VARCHAR(255) NOT NULL,
-        done BOOLEAN NOT NULL DEFAULT FALSE
-    );`
+    UPDATE tasks SET title = $2, description = $3, done = $4, due_at = $5, updated_at = $6,
+        completed_at = CASE WHEN $4 THEN COALESCE(completed_at, $6) ELSE NULL END,
+        priority = $7, project_id = $8, parent_id = $9, recurrence = $10
+    WHERE id = $1 AND deleted_at IS NULL
+    RETURNING ` + taskColumns
+
+    at := now()
+    row := tx.queryRow(ctx, query, task.ID, task.Title, task.Description, task.Done, utc(task.DueAt), at, task.Priority, task.ProjectID, task.ParentID, task.Recurrence)
+    err = scanTask(row, &updated)
+
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return updated, ErrTaskNotFound
+        }
+        return updated, fmt.Errorf("failed to update task: %w", err)
+    }
+
+    if err := r.replaceTags(ctx, tx, updated.ID, tags); err != nil {
+        return updated, fmt.Errorf("failed to update task: %w", err)
+    }
+    if task.Done != wasDone {
+        if err := r.recordEvent(ctx, tx, task.ID, task.Done, actor, at); err != nil {
+            return updated, fmt.Errorf("failed to update task: %w", err)
+        }
+    }
+    if completing {
+        if err := r.scheduleNext(ctx, tx, updated, at); err != nil {
+            return updated, fmt.Errorf("failed to update task: %w", err)
+        }
+    }
+    if err := tx.commit(ctx); err != nil {
+        return updated, fmt.Errorf("failed to update task: %w", err)
+    }
+    updated.Tags = tags
+
+    tasks := []model.Task{updated}
+    if err := r.loadBlockers(ctx, tasks); err != nil {
+        return updated, fmt.Errorf("failed to get task: %w", err)
+    }
 
-    _, err := db.Exec(ctx, query)
+    log.Println("Task updated successfully")
+    return tasks[0], nil
+}
+
+func (r *sqlRepository) Delete(ctx context.Context, id int) error {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    n, err := r.db.exec(ctx, trashTaskQuery, id, now())
     if err != nil {
-        return fmt.Errorf("failed to create table: %w", err)
+        return fmt.Errorf("failed to delete task: %w", err)
+    }
+    // The subtasks of a task in the trash are in the trash too, so no row is
+    // affected only if the task is missing.
+    if n == 0 {
+        return ErrTaskNotFound
     }
 
-    log.Println("Table 'tasks' is ensured to exist")
+    log.Println("Task moved to trash")
     return nil
 }
 
-func (r *PostgresTaskRepository) GetAll() ([]model.Task, error) {
-    var tasks []model.Task
+func (r *sqlRepository) Trash(ctx context.Context, expr filter.Expr, page Page) ([]model.Task, error) {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
 
-    query := `SELECT id, title, done FROM tasks`
-    rows, err := r.db.Query(context.Background(), query)
+    query, args := buildListQuery(r.dialect.name, expr, page, true)
+    return r.listTasks(ctx, query, args)
+}
+
+func (r *sqlRepository) Restore(ctx context.Context, id int) error {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    tx, err := r.db.begin(ctx)
     if err != nil {
-        return nil, fmt.Errorf("failed to get tasks: %w", err)
+        return fmt.Errorf("failed to restore task: %w", err)
     }
-    defer rows.Close()
+    defer tx.rollback(ctx)
 
-    for rows.Next() {
-        var task model.Task
-        if err := rows.Scan(&task.ID, &task.Title, &task.Done); err != nil {
-            return nil, fmt.Errorf("failed to scan task: %w", err)
+    var deletedAt time.Time
+    var parentID *int
+    err = tx.queryRow(ctx, trashedTaskQuery+r.dialect.lockRow, id).Scan(&deletedAt, &parentID)
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return ErrTaskNotFound
         }
-        tasks = append(tasks, task)
+        return fmt.Errorf("failed to restore task: %w", err)
     }
 
-    return tasks, nil
+    if parentID != nil {
+        var exists bool
+        if err := tx.queryRow(ctx, taskExistsQuery, *parentID).Scan(&exists); err != nil {
+            return fmt.Errorf("failed to restore task: %w", err)
+        }
+        if !exists {
+            return ErrParentDeleted
+        }
+    }
+
+    // SQLite compares timestamps as text, so deleted_at is matched in the
+    // UTC form it was stored in.
+    if _, err := tx.exec(ctx, restoreTaskQuery, id, deletedAt.UTC()); err != nil {
+        return fmt.Errorf("failed to restore task: %w", err)
+    }
+    if err := tx.commit(ctx); err != nil {
+        return fmt.Errorf("failed to restore task: %w", err)
+    }
+
+    log.Println("Task restored successfully")
+    return nil
 }
 
-func (r *PostgresTaskRepository) GetByID(id int) (model.Task, error) {
-    var task model.Task
-    query := `SELECT id, title, done FROM tasks WHERE id = $1`
+func (r *sqlRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    n, err := r.db.exec(ctx, purgeTasksQuery, before.UTC())
+    if err != nil {
+        return 0, fmt.Errorf("failed to purge tasks: %w", err)
+    }
+
+    return int(n), nil
+}
 
-    row := r.db.QueryRow(context.Background(), query, id)
-    err := row.Scan(&task.ID, &task.Title, &task.Done)
+func (r *sqlRepository) MarkDone(ctx context.Context, id int, actor string, opts DoneOptions) error {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
 
+    tx, err := r.db.begin(ctx)
     if err != nil {
-        if err == sql.ErrNoRows {
-            return task, fmt.Errorf("task not found")
+        return fmt.Errorf("failed to mark task as done: %w", err)
+    }
+    defer tx.rollback(ctx)
+
+    var exists bool
+    if err := tx.queryRow(ctx, taskExistsQuery, id).Scan(&exists); err != nil {
+        return fmt.Errorf("failed to mark task as done: %w", err)
+    }
+    if !exists {
+        return ErrTaskNotFound
+    }
+
+    open, err := r.openDescendants(ctx, tx, id)
+    if err != nil {
+        return fmt.Errorf("failed to mark task as done: %w", err)
+    }
+    if len(open) > 0 && !opts.Cascade {
+        return ErrOpenSubtasks
+    }
+
+    ids := append(open, id)
+    if !opts.Force {
+        err := checkUnblocked(ids, func(id int) ([]int, error) {
+            return r.queryIDs(ctx, tx, openBlockersQuery, id)
+        })
+        if err != nil {
+            return err
         }
-        return task, fmt.Errorf("failed to get task: %w", err)
     }
-    
-    return task, nil
+
+    at := now()
+    for _, taskID := range ids {
+        if err := r.completeTask(ctx, tx, taskID, actor, at); err != nil {
+            return fmt.Errorf("failed to mark task as done: %w", err)
+        }
+    }
+
+    if err := tx.commit(ctx); err != nil {
+        return fmt.Errorf("failed to mark task as done: %w", err)
+    }
+
+    log.Println("Task marked as done")
+    return nil
 }
 
-func (r *PostgresTaskRepository) Add(task model.Task) error {
-    query := `INSERT INTO tasks (title, done) VALUES ($1, $2)`
-    _, err := r.db.Exec(context.Background(), query, task.Title, task.Done)
+func (r *sqlRepository) Reopen(ctx context.Context, id int, actor string) error {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    tx, err := r.db.begin(ctx)
     if err != nil {
-        return fmt.Errorf("failed to add task: %w", err)
+        return fmt.Errorf("failed to reopen task: %w", err)
     }
+    defer tx.rollback(ctx)
 
-    log.Println("Task added successfully")
+    found, err := r.setDone(ctx, tx, id, false, actor, now())
+    if err != nil {
+        return fmt.Errorf("failed to reopen task: %w", err)
+    }
+    if !found {
+        return ErrTaskNotFound
+    }
+
+    if err := tx.commit(ctx); err != nil {
+        return fmt.Errorf("failed to reopen task: %w", err)
+    }
+
+    log.Println("Task reopened")
     return nil
 }
 
-func (r *PostgresTaskRepository) Delete(id int) error {
-    query := `DELETE FROM tasks WHERE id = $1`
-    _, err := r.db.Exec(context.Background(), query, id)
+// setDone changes the task status and records the transition in task_events.
+// A task that already has the status is left as it is.
+// It reports whether the task exists.
+func (r *sqlRepository) setDone(ctx context.Context, tx txRunner, id int, done bool, actor string, at time.Time) (bool, error) {
+    query := `
+    UPDATE tasks SET done = $2, updated_at = $3,
+        completed_at = CASE WHEN $2 THEN COALESCE(completed_at, $3) ELSE NULL END
+    WHERE id = $1 AND deleted_at IS NULL AND done <> $2`
+
+    n, err := tx.exec(ctx, query, id, done, at)
     if err != nil {
-        return fmt.Errorf("failed to delete task: %w", err)
+        return false, err
+    }
+    if n == 0 {
+        var exists bool
+        err := tx.queryRow(ctx, taskExistsQuery, id).Scan(&exists)
+        return exists, err
+    }
+
+    if err := r.recordEvent(ctx, tx, id, done, actor, at); err != nil {
+        return false, err
+    }
+    return true, nil
+}
+
+// recordEvent adds a change of the task status to task_events.
+func (r *sqlRepository) recordEvent(ctx context.Context, tx txRunner, id int, done bool, actor string, at time.Time) error {
+    query := `INSERT INTO task_events (task_id, action, actor, created_at) VALUES ($1, $2, $3, $4)`
+    _, err := tx.exec(ctx, query, id, statusAction(done), actor, at)
+    return err
+}
+
+// insertTask stores a new task created at the given time and returns it as
+// stored, without its tags.
+func (r *sqlRepository) insertTask(ctx context.Context, tx txRunner, task model.Task, at time.Time) (model.Task, error) {
+    query := `
+    INSERT INTO tasks (title, description, done, due_at, created_at, updated_at, completed_at, priority, project_id, parent_id, recurrence)
+    VALUES ($1, $2, $3, $4, $5, $5, $6, $7, $8, $9, $10)
+    RETURNING ` + taskColumns
+
+    var created model.Task
+    row := tx.queryRow(ctx, query, task.Title, task.Description, task.Done, utc(task.DueAt), at, completedAt(task.Done, at), task.Priority, task.ProjectID, task.ParentID, task.Recurrence)
+    err := scanTask(row, &created)
+    return created, err
+}
+
+// completeTask marks a task done and, if it was an open recurring task,
+// creates its next occurrence.
+func (r *sqlRepository) completeTask(ctx context.Context, tx txRunner, id int, actor string, at time.Time) error {
+    var task model.Task
+    if err := scanTask(tx.queryRow(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = $1 AND deleted_at IS NULL`, id), &task); err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return nil
+        }
+        return err
+    }
+
+    if _, err := r.setDone(ctx, tx, id, true, actor, at); err != nil {
+        return err
+    }
+    if task.Done {
+        return nil
+    }
+    return r.scheduleNext(ctx, tx, task, at)
+}
+
+// scheduleNext creates the next occurrence of a recurring task completed at
+// the given time, with the same tags.
+func (r *sqlRepository) scheduleNext(ctx context.Context, tx txRunner, task model.Task, at time.Time) error {
+    next, ok := nextOccurrence(task, at)
+    if !ok {
+        return nil
+    }
+
+    created, err := r.insertTask(ctx, tx, next, at)
+    if err != nil {
+        return err
+    }
+    if _, err := tx.exec(ctx, copyTagsQuery, task.ID, created.ID); err != nil {
+        return err
     }
 
-    log.Println("Task deleted successfully")
+    log.Printf("Next occurrence of task %d scheduled as task %d", task.ID, created.ID)
     return nil
 }
 
-func (r *PostgresTaskRepository) MarkDone(id int) error {
-    query := `UPDATE tasks SET done = TRUE WHERE id = $1`
-    _, err := r.db.Exec(context.Background(), query, id)
+// openDescendants returns the IDs of the tasks below id that are not done.
+func (r *sqlRepository) openDescendants(ctx context.Context, tx txRunner, id int) ([]int, error) {
+    return r.queryIDs(ctx, tx, openDescendantsQuery, id)
+}
+
+// queryIDs runs a query selecting a single column of task IDs.
+func (r *sqlRepository) queryIDs(ctx context.Context, tx txRunner, query string, args ...interface{}) ([]int, error) {
+    rows, err := tx.query(ctx, query, args...)
     if err != nil {
-        return fmt.Errorf("failed to mark task as done: %w", err)
+        return nil, err
+    }
+    defer rows.Close()
+
+    var ids []int
+    for rows.Next() {
+        var taskID int
+        if err := rows.Scan(&taskID); err != nil {
+            return nil, err
+        }
+        ids = append(ids, taskID)
     }
 
-    log.Println("Task marked as done")
+    return ids, rows.Err()
+}
+
+// checkParent returns an error if parentID is set but names no task, or if
+// making it the parent of taskID would create a cycle. taskID is 0 for new tasks.
+func (r *sqlRepository) checkParent(ctx context.Context, tx txRunner, taskID int, parentID *int) error {
+    if parentID == nil {
+        return nil
+    }
+    if *parentID == taskID {
+        return ErrOwnParent
+    }
+
+    var exists bool
+    if err := tx.queryRow(ctx, taskExistsQuery, *parentID).Scan(&exists); err != nil {
+        return fmt.Errorf("failed to check parent task: %w", err)
+    }
+    if !exists {
+        return ErrUnknownParent
+    }
+
+    if taskID == 0 {
+        return nil
+    }
+
+    var cycle bool
+    if err := tx.queryRow(ctx, isDescendantQuery, taskID, *parentID).Scan(&cycle); err != nil {
+        return fmt.Errorf("failed to check parent task: %w", err)
+    }
+    if cycle {
+        return ErrParentIsSubtask
+    }
     return nil
 }
 
-func (r *PostgresTaskRepository) GetFiltered(done *bool) ([]model.Task, error) {
-    var tasks []model.Task
-    query := "SELECT id, title, done FROM tasks"
-    var args []interface{}
+// checkCompletable returns an error if an update may not mark an open task
+// done because a task below it or one of its blockers is still open.
+func (r *sqlRepository) checkCompletable(ctx context.Context, tx txRunner, id int) error {
+    open, err := r.openDescendants(ctx, tx, id)
+    if err != nil {
+        return fmt.Errorf("failed to check subtasks: %w", err)
+    }
+    if len(open) > 0 {
+        return ErrOpenSubtasks
+    }
+
+    return checkUnblocked([]int{id}, func(id int) ([]int, error) {
+        return r.queryIDs(ctx, tx, openBlockersQuery, id)
+    })
+}
+
+func (r *sqlRepository) History(ctx context.Context, id int) ([]model.TaskEvent, error) {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
 
-    if done != nil {
-        query += " WHERE done = $1"
-        args = append(args, *done)
+    if _, err := r.GetByID(ctx, id); err != nil {
+        return nil, err
     }
 
-    rows, err := r.db.Query(context.Background(), query, args...)
+    var events []model.TaskEvent
+    query := `SELECT id, task_id, action, actor, created_at FROM task_events WHERE task_id = $1 ORDER BY id`
+
+    rows, err := r.db.query(ctx, query, id)
+    if err != nil {
+        return nil, fmt.Errorf("failed to query task history: %w", err)
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var event model.TaskEvent
+        if err := rows.Scan(&event.ID, &event.TaskID, &event.Action, &event.Actor, &event.CreatedAt); err != nil {
+            return nil, fmt.Errorf("failed to scan task event: %w", err)
+        }
+        events = append(events, event)
+    }
+
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("rows iteration error: %w", err)
+    }
+
+    return events, nil
+}
+
+func statusAction(done bool) string {
+    if done {
+        return model.TaskEventDone
+    }
+    return model.TaskEventReopened
+}
+
+func (r *sqlRepository) GetFiltered(ctx context.Context, expr filter.Expr, page Page) ([]model.Task, error) {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    query, args := buildListQuery(r.dialect.name, expr, page, false)
+    return r.listTasks(ctx, query, args)
+}
+
+// listTasks runs a query built by buildListQuery and fills in the tags and
+// blockers of the tasks.
+func (r *sqlRepository) listTasks(ctx context.Context, query string, args []interface{}) ([]model.Task, error) {
+    var tasks []model.Task
+
+    rows, err := r.db.query(ctx, query, args...)
     if err != nil {
         return nil, fmt.Errorf("failed to query tasks: %w", err)
     }
@@ -153,7 +670,7 @@ func (r *PostgresTaskRepository) GetFiltered(done *bool) ([]model.Task, error) {
 
     for rows.Next() {
         var task model.Task
-        if err := rows.Scan(&task.ID, &task.Title, &task.Done); err != nil {
+        if err := scanTask(rows, &task); err != nil {
             return nil, fmt.Errorf("failed to scan task: %w", err)
         }
         tasks = append(tasks, task)
@@ -163,5 +680,344 @@ func (r *PostgresTaskRepository) GetFiltered(done *bool) ([]model.Task, error) {
         return nil, fmt.Errorf("rows iteration error: %w", err)
     }
 
+    if err := r.loadTags(ctx, tasks); err != nil {
+        return nil, fmt.Errorf("failed to query task tags: %w", err)
+    }
+    if err := r.loadBlockers(ctx, tasks); err != nil {
+        return nil, fmt.Errorf("failed to query task blockers: %w", err)
+    }
+
     return tasks, nil
-}
\ No newline at end of file
+}
+
+func (r *sqlRepository) Subtree(ctx context.Context, id int) (model.TaskTree, error) {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    root, err := r.GetByID(ctx, id)
+    if err != nil {
+        return model.TaskTree{}, err
+    }
+
+    var descendants []model.Task
+    rows, err := r.db.query(ctx, subtreeQuery, id)
+    if err != nil {
+        return model.TaskTree{}, fmt.Errorf("failed to query subtasks: %w", err)
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var task model.Task
+        if err := scanTask(rows, &task); err != nil {
+            return model.TaskTree{}, fmt.Errorf("failed to scan task: %w", err)
+        }
+        descendants = append(descendants, task)
+    }
+
+    if err := rows.Err(); err != nil {
+        return model.TaskTree{}, fmt.Errorf("rows iteration error: %w", err)
+    }
+
+    if err := r.loadTags(ctx, descendants); err != nil {
+        return model.TaskTree{}, fmt.Errorf("failed to query task tags: %w", err)
+    }
+    if err := r.loadBlockers(ctx, descendants); err != nil {
+        return model.TaskTree{}, fmt.Errorf("failed to query task blockers: %w", err)
+    }
+
+    return buildTree(root, descendants), nil
+}
+
+func (r *sqlRepository) AddTag(ctx context.Context, taskID int, tag string) error {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    tag, err := model.NormalizeTag(tag)
+    if err != nil {
+        return invalid(err)
+    }
+
+    tx, err := r.db.begin(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to tag task: %w", err)
+    }
+    defer tx.rollback(ctx)
+
+    // The task's updated_at is bumped first, which also tells whether it exists.
+    // The transaction is rolled back if the task already had the tag.
+    touched, err := tx.exec(ctx, touchTaskQuery, taskID, now())
+    if err != nil {
+        return fmt.Errorf("failed to tag task: %w", err)
+    }
+    if touched == 0 {
+        return ErrTaskNotFound
+    }
+
+    if _, err := tx.exec(ctx, insertTagQuery, tag); err != nil {
+        return fmt.Errorf("failed to tag task: %w", err)
+    }
+
+    linked, err := tx.exec(ctx, linkTagQuery, taskID, tag)
+    if err != nil {
+        return fmt.Errorf("failed to tag task: %w", err)
+    }
+    if linked == 0 {
+        return nil
+    }
+
+    if err := tx.commit(ctx); err != nil {
+        return fmt.Errorf("failed to tag task: %w", err)
+    }
+
+    log.Println("Task tagged successfully")
+    return nil
+}
+
+func (r *sqlRepository) RemoveTag(ctx context.Context, taskID int, tag string) error {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    tag, err := model.NormalizeTag(tag)
+    if err != nil {
+        return invalid(err)
+    }
+
+    tx, err := r.db.begin(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to untag task: %w", err)
+    }
+    defer tx.rollback(ctx)
+
+    touched, err := tx.exec(ctx, touchTaskQuery, taskID, now())
+    if err != nil {
+        return fmt.Errorf("failed to untag task: %w", err)
+    }
+    if touched == 0 {
+        return ErrTaskNotFound
+    }
+
+    unlinked, err := tx.exec(ctx, unlinkTagQuery, taskID, tag)
+    if err != nil {
+        return fmt.Errorf("failed to untag task: %w", err)
+    }
+    if unlinked == 0 {
+        return nil
+    }
+
+    if err := tx.commit(ctx); err != nil {
+        return fmt.Errorf("failed to untag task: %w", err)
+    }
+
+    log.Println("Task untagged successfully")
+    return nil
+}
+
+func (r *sqlRepository) Tags(ctx context.Context) ([]model.TagCount, error) {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    tags := []model.TagCount{}
+
+    rows, err := r.db.query(ctx, tagCountsQuery)
+    if err != nil {
+        return nil, fmt.Errorf("failed to query tags: %w", err)
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var tag model.TagCount
+        if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+            return nil, fmt.Errorf("failed to scan tag: %w", err)
+        }
+        tags = append(tags, tag)
+    }
+
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("rows iteration error: %w", err)
+    }
+
+    return tags, nil
+}
+
+func (r *sqlRepository) AddBlocker(ctx context.Context, taskID, blockerID int) error {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    if taskID == blockerID {
+        return ErrSelfBlocker
+    }
+
+    tx, err := r.db.begin(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to add blocker: %w", err)
+    }
+    defer tx.rollback(ctx)
+
+    if r.dialect.lockDependencies != "" {
+        if _, err := tx.exec(ctx, r.dialect.lockDependencies); err != nil {
+            return fmt.Errorf("failed to add blocker: %w", err)
+        }
+    }
+
+    touched, err := tx.exec(ctx, touchTaskQuery, taskID, now())
+    if err != nil {
+        return fmt.Errorf("failed to add blocker: %w", err)
+    }
+    if touched == 0 {
+        return ErrTaskNotFound
+    }
+
+    var exists bool
+    if err := tx.queryRow(ctx, taskExistsQuery, blockerID).Scan(&exists); err != nil {
+        return fmt.Errorf("failed to add blocker: %w", err)
+    }
+    if !exists {
+        return ErrBlockerNotFound
+    }
+
+    var cycle bool
+    if err := tx.queryRow(ctx, dependsOnQuery, blockerID, taskID).Scan(&cycle); err != nil {
+        return fmt.Errorf("failed to add blocker: %w", err)
+    }
+    if cycle {
+        return ErrDependencyCycle
+    }
+
+    linked, err := tx.exec(ctx, linkBlockerQuery, taskID, blockerID, now())
+    if err != nil {
+        return fmt.Errorf("failed to add blocker: %w", err)
+    }
+    if linked == 0 {
+        return nil
+    }
+
+    if err := tx.commit(ctx); err != nil {
+        return fmt.Errorf("failed to add blocker: %w", err)
+    }
+
+    log.Println("Task blocker added successfully")
+    return nil
+}
+
+func (r *sqlRepository) RemoveBlocker(ctx context.Context, taskID, blockerID int) error {
+    ctx, cancel := withTimeout(ctx, r.queryTimeout)
+    defer cancel()
+
+    tx, err := r.db.begin(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to remove blocker: %w", err)
+    }
+    defer tx.rollback(ctx)
+
+    touched, err := tx.exec(ctx, touchTaskQuery, taskID, now())
+    if err != nil {
+        return fmt.Errorf("failed to remove blocker: %w", err)
+    }
+    if touched == 0 {
+        return ErrTaskNotFound
+    }
+
+    unlinked, err := tx.exec(ctx, unlinkBlockerQuery, taskID, blockerID)
+    if err != nil {
+        return fmt.Errorf("failed to remove blocker: %w", err)
+    }
+    if unlinked == 0 {
+        return nil
+    }
+
+    if err := tx.commit(ctx); err != nil {
+        return fmt.Errorf("failed to remove blocker: %w", err)
+    }
+
+    log.Println("Task blocker removed successfully")
+    return nil
+}
+
+// checkProject returns an error if projectID is set but names no project.
+func (r *sqlRepository) checkProject(ctx context.Context, tx txRunner, projectID *int) error {
+    if projectID == nil {
+        return nil
+    }
+
+    // The shared lock keeps a concurrent DeleteProject from removing the
+    // project before the task referencing it is committed.
+    var id int
+    err := tx.queryRow(ctx, projectExistsQuery+r.dialect.shareRow, *projectID).Scan(&id)
+    if errors.Is(err, sql.ErrNoRows) {
+        return ErrUnknownProject
+    }
+    if err != nil {
+        return fmt.Errorf("failed to check project: %w", err)
+    }
+    return nil
+}
+
+// replaceTags sets the tags of a task to exactly tags.
+func (r *sqlRepository) replaceTags(ctx context.Context, tx txRunner, taskID int, tags []string) error {
+    if _, err := tx.exec(ctx, unlinkAllTagsQuery, taskID); err != nil {
+        return err
+    }
+
+    for _, tag := range tags {
+        if _, err := tx.exec(ctx, insertTagQuery, tag); err != nil {
+            return err
+        }
+        if _, err := tx.exec(ctx, linkTagQuery, taskID, tag); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// loadTags fills in the tags of tasks. It must not be called inside a
+// transaction, which would hold the only SQLite connection.
+func (r *sqlRepository) loadTags(ctx context.Context, tasks []model.Task) error {
+    if len(tasks) == 0 {
+        return nil
+    }
+
+    query, args, byID := tagsQuery(tasks)
+    rows, err := r.db.query(ctx, query, args...)
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var taskID int
+        var name string
+        if err := rows.Scan(&taskID, &name); err != nil {
+            return err
+        }
+        byID[taskID].Tags = append(byID[taskID].Tags, name)
+    }
+
+    return rows.Err()
+}
+
+// loadBlockers fills in the blockers of tasks. Like loadTags, it must not be
+// called inside a transaction.
+func (r *sqlRepository) loadBlockers(ctx context.Context, tasks []model.Task) error {
+    if len(tasks) == 0 {
+        return nil
+    }
+
+    query, args, byID := blockersQuery(tasks)
+    rows, err := r.db.query(ctx, query, args...)
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var taskID, blockerID int
+        var blockerDone bool
+        if err := rows.Scan(&taskID, &blockerID, &blockerDone); err != nil {
+            return err
+        }
+        addBlocker(byID[taskID], blockerID, blockerDone)
+    }
+
+    return rows.Err()
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+    "fmt"
+    "strings"
+
+    "todo-golang/internal/model"
+)
+
+// Statements shared by the SQL repositories for maintaining task tags. Tags
+// are created on first use; a tag that no task carries any more is kept but
+// no longer listed.
+const (
+    insertTagQuery     = `INSERT INTO tags (name) VALUES ($1) ON CONFLICT (name) DO NOTHING`
+    linkTagQuery       = `
+    INSERT INTO task_tags (task_id, tag_id)
+    SELECT $1, id FROM tags WHERE name = $2
+    ON CONFLICT DO NOTHING`
+    unlinkTagQuery     = `DELETE FROM task_tags WHERE task_id = $1 AND tag_id IN (SELECT id FROM tags WHERE name = $2)`
+    unlinkAllTagsQuery = `DELETE FROM task_tags WHERE task_id = $1`
+    copyTagsQuery      = `INSERT INTO task_tags (task_id, tag_id) SELECT $2, tag_id FROM task_tags WHERE task_id = $1`
+    touchTaskQuery     = `UPDATE tasks SET updated_at = $2 WHERE id = $1 AND deleted_at IS NULL`
+    tagCountsQuery     = `
+    SELECT tg.name, COUNT(*) FROM tags tg
+    JOIN task_tags tt ON tt.tag_id = tg.id
+    JOIN tasks t ON t.id = tt.task_id AND t.deleted_at IS NULL
+    GROUP BY tg.name
+    ORDER BY tg.name`
+)
+
+// tagsQuery returns the query selecting the tags of tasks, ordered by name,
+// and an index of the tasks by ID. Every task's tag list is reset to an empty
+// list, so that tasks without tags report [] rather than null.
+func tagsQuery(tasks []model.Task) (string, []interface{}, map[int]*model.Task) {
+    byID := make(map[int]*model.Task, len(tasks))
+    placeholders := make([]string, len(tasks))
+    args := make([]interface{}, len(tasks))
+
+    for i := range tasks {
+        tasks[i].Tags = []string{}
+        byID[tasks[i].ID] = &tasks[i]
+        placeholders[i] = fmt.Sprintf("$%d", i+1)
+        args[i] = tasks[i].ID
+    }
+
+    query := `
+    SELECT tt.task_id, tg.name FROM task_tags tt
+    JOIN tags tg ON tg.id = tt.tag_id
+    WHERE tt.task_id IN (` + strings.Join(placeholders, ", ") + `)
+    ORDER BY tg.name`
+
+    return query, args, byID
+}
@@ -0,0 +1,26 @@
+package storage
+
+// Statements shared by the SQL repositories for moving tasks to the trash and
+// back. A task is deleted together with its subtasks, and restoring it brings
+// back the subtasks that were deleted at the same time.
+const (
+    trashTaskQuery = descendantsCTE + `
+    UPDATE tasks SET deleted_at = $2
+    WHERE (id = $1 OR id IN (SELECT id FROM descendants)) AND deleted_at IS NULL`
+    restoreTaskQuery = descendantsCTE + `
+    UPDATE tasks SET deleted_at = NULL
+    WHERE (id = $1 OR id IN (SELECT id FROM descendants)) AND deleted_at = $2`
+    trashedTaskQuery = `SELECT deleted_at, parent_id FROM tasks WHERE id = $1 AND deleted_at IS NOT NULL`
+    purgeTasksQuery  = `DELETE FROM tasks WHERE deleted_at < $1`
+    // trashProjectQuery moves the tasks of project $1 to the trash along with
+    // their subtasks, which may belong to other projects.
+    trashProjectQuery = `
+    WITH RECURSIVE doomed (id) AS (
+        SELECT id FROM tasks WHERE project_id = $1 AND deleted_at IS NULL
+        UNION
+        SELECT t.id FROM tasks t JOIN doomed d ON t.parent_id = d.id
+    )
+    UPDATE tasks SET deleted_at = $2
+    WHERE id IN (SELECT id FROM doomed) AND deleted_at IS NULL`
+    detachProjectQuery = `UPDATE tasks SET project_id = NULL WHERE project_id = $1`
+)
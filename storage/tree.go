@@ -0,0 +1,42 @@
+package storage
+
+import "todo-golang/internal/model"
+
+// descendantsCTE is a WITH clause naming the IDs of all tasks below task $1
+// in the hierarchy. UNION rather than UNION ALL keeps it finite even if the
+// data contained a cycle.
+const descendantsCTE = `
+    WITH RECURSIVE descendants (id) AS (
+        SELECT id FROM tasks WHERE parent_id = $1
+        UNION
+        SELECT t.id FROM tasks t JOIN descendants d ON t.parent_id = d.id
+    )`
+
+// Tasks in the trash are left out of subtrees, but still count when looking
+// for cycles, as they may be restored.
+const (
+    subtreeQuery         = descendantsCTE + ` SELECT ` + taskColumns + ` FROM tasks WHERE id IN (SELECT id FROM descendants) AND deleted_at IS NULL ORDER BY id`
+    openDescendantsQuery = descendantsCTE + ` SELECT id FROM tasks WHERE id IN (SELECT id FROM descendants) AND done = FALSE AND deleted_at IS NULL ORDER BY id`
+    isDescendantQuery    = descendantsCTE + ` SELECT EXISTS (SELECT 1 FROM descendants WHERE id = $2)`
+    taskExistsQuery      = `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND deleted_at IS NULL)`
+)
+
+// buildTree arranges the descendants of root, in any order, under their parents.
+// Subtasks are ordered by ID.
+func buildTree(root model.Task, descendants []model.Task) model.TaskTree {
+    children := make(map[int][]model.Task)
+    for _, task := range descendants {
+        children[*task.ParentID] = append(children[*task.ParentID], task)
+    }
+
+    var build func(task model.Task) model.TaskTree
+    build = func(task model.Task) model.TaskTree {
+        tree := model.TaskTree{Task: task, Subtasks: []model.TaskTree{}}
+        for _, child := range children[task.ID] {
+            tree.Subtasks = append(tree.Subtasks, build(child))
+        }
+        return tree
+    }
+
+    return build(root)
+}